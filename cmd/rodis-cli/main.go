@@ -0,0 +1,59 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+// Command rodis-cli is an offline maintenance tool for a rodis data
+// directory. It must not be pointed at a directory a rodis-storaged or rodis
+// instance already has open, since goleveldb only allows one process to hold
+// a data directory at a time.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rod6/rodis/command"
+	"github.com/rod6/rodis/storage"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "dump":
+		dump(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: rodis-cli dump -db <path> -out <rdb-file>")
+}
+
+// dump -> write every hash in the goleveldb store at -db to an RDB file at
+// -out, the same format SAVE/BGSAVE produce.
+func dump(args []string) {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	dbPath := fs.String("db", "rodis.db", "path to the goleveldb data directory")
+	out := fs.String("out", "dump.rdb", "RDB file to write")
+	fs.Parse(args)
+
+	db, err := storage.NewLevelDB(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rodis-cli: open %s: %v\n", *dbPath, err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := command.DumpRDB(db, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "rodis-cli: dump: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+// Command rodis-storaged serves a local goleveldb store over gRPC, so
+// multiple rodis command-frontends can share one persistent store.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/rod6/rodis/command"
+	"github.com/rod6/rodis/storage"
+	"github.com/rod6/rodis/storage/remote"
+)
+
+func main() {
+	addr := flag.String("listen", ":6380", "address to listen on")
+	dbPath := flag.String("db", "rodis.db", "path to the goleveldb data directory")
+	rdbLoad := flag.String("rdb-load", "", "RDB file to load into the store before serving; empty skips loading")
+	certFile := flag.String("cert", "", "TLS certificate file; set together with -key and -ca to require mTLS")
+	keyFile := flag.String("key", "", "TLS private key file")
+	caFile := flag.String("ca", "", "CA certificate used to verify client certificates")
+	flag.Parse()
+
+	db, err := storage.NewLevelDB(*dbPath)
+	if err != nil {
+		log.Fatalf("rodis-storaged: open %s: %v", *dbPath, err)
+	}
+	defer db.Close()
+
+	if *rdbLoad != "" {
+		if err := command.LoadRDB(db, *rdbLoad); err != nil {
+			log.Fatalf("rodis-storaged: load %s: %v", *rdbLoad, err)
+		}
+		log.Printf("rodis-storaged: loaded %s", *rdbLoad)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("rodis-storaged: listen %s: %v", *addr, err)
+	}
+
+	creds, err := serverCredentials(*certFile, *keyFile, *caFile)
+	if err != nil {
+		log.Fatalf("rodis-storaged: %v", err)
+	}
+
+	log.Printf("rodis-storaged: serving %s on %s", *dbPath, *addr)
+	if err := remote.Serve(lis, remote.NewServer(db), creds); err != nil {
+		log.Fatalf("rodis-storaged: %v", err)
+	}
+}
+
+// serverCredentials builds mTLS transport credentials when certFile, keyFile
+// and caFile are all set, or returns nil (plaintext) when none of them are.
+func serverCredentials(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	caCert, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("rodis-storaged: failed to parse CA certificate")
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}), nil
+}
@@ -7,11 +7,18 @@
 package command
 
 import (
+	"encoding/hex"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/rod6/rodis/resp"
 )
 
+// hashScanBatch is the number of fields fetched per storage round-trip while
+// paging through a hash for HKEYS/HVALS/HSCAN.
+const hashScanBatch = 100
+
 // command
 // ------------
 // HDEL
@@ -28,7 +35,20 @@ import (
 // HSET
 // HSETNX
 // HSTRLEN
-// HVALS
+//
+// Every handler's body below runs through Queue, so that inside a MULTI it
+// still executes against the CacheDB overlay right away (keeping later
+// reads in the same transaction consistent) while its RESP reply is
+// captured for EXEC to replay instead of reaching the client immediately.
+//
+// Going through the Backend interface, on its own, only buys isolation -
+// CacheDB makes sure a queued write doesn't touch the real store until
+// EXEC. It says nothing about which half of a handler's own reply the
+// client sees before EXEC runs, which is Queue's actual job: without it, a
+// queued HSET would write its integer reply straight to the client the
+// moment it's issued, instead of "+QUEUED", with the real reply showing up a
+// second time inside EXEC's array. So every handler does need to call Queue,
+// same as save/bgsave/debug in persistence.go.
 
 // hdel -> https://redis.io/commands/hdel
 func hdel(v Args, ex *Extras) error {
@@ -36,91 +56,99 @@ func hdel(v Args, ex *Extras) error {
 		return resp.NewError(ErrFmtWrongNumberArgument, "hdel").WriteTo(ex.Buffer)
 	}
 
-	ex.DB.Lock()
-	defer ex.DB.Unlock()
+	return Queue(func() error {
+		ex.DB.Lock()
+		defer ex.DB.Unlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if !keyExists {
-		return resp.ZeroInteger.WriteTo(ex.Buffer)
-	}
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		keyExists, tipe := ex.DB.Has(v[0])
+		if !keyExists {
+			return resp.ZeroInteger.WriteTo(ex.Buffer)
+		}
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	fields := [][]byte{}
-	for _, field := range v[1:] {
-		fields = append(fields, []byte(field))
-	}
-	hash := ex.DB.GetFields(v[0], fields)
+		fields := [][]byte{}
+		for _, field := range v[1:] {
+			fields = append(fields, []byte(field))
+		}
+		hash := ex.DB.GetFields(v[0], fields)
 
-	count := 0
-	for _, value := range hash {
-		if len(value) != 0 {
-			count++
+		count := 0
+		for _, value := range hash {
+			if len(value) != 0 {
+				count++
+			}
 		}
-	}
-	ex.DB.DeleteFields(v[0], fields)
-	return resp.Integer(count).WriteTo(ex.Buffer)
+		ex.DB.DeleteFields(v[0], fields)
+		return resp.Integer(count).WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hexists -> https://redis.io/commands/hexist
 func hexists(v Args, ex *Extras) error {
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		keyExists, tipe := ex.DB.Has(v[0])
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
-	if len(hash[string(v[1])]) == 0 {
-		return resp.ZeroInteger.WriteTo(ex.Buffer)
-	}
-	return resp.OneInteger.WriteTo(ex.Buffer)
+		hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
+		if len(hash[string(v[1])]) == 0 {
+			return resp.ZeroInteger.WriteTo(ex.Buffer)
+		}
+		return resp.OneInteger.WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hget -> https://redis.io/commands/hget
 func hget(v Args, ex *Extras) error {
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if !keyExists {
-		return resp.NilBulkString.WriteTo(ex.Buffer)
-	}
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		keyExists, tipe := ex.DB.Has(v[0])
+		if !keyExists {
+			return resp.NilBulkString.WriteTo(ex.Buffer)
+		}
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
-	if len(hash[string(v[1])]) == 0 {
-		return resp.NilBulkString.WriteTo(ex.Buffer)
-	}
+		hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
+		if len(hash[string(v[1])]) == 0 {
+			return resp.NilBulkString.WriteTo(ex.Buffer)
+		}
 
-	return resp.BulkString(hash[string(v[1])]).WriteTo(ex.Buffer)
+		return resp.BulkString(hash[string(v[1])]).WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hgetall -> https://redis.io/commands/hgetall
 func hgetall(v Args, ex *Extras) error {
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if !keyExists {
-		return resp.EmptyArray.WriteTo(ex.Buffer)
-	}
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		keyExists, tipe := ex.DB.Has(v[0])
+		if !keyExists {
+			return resp.EmptyArray.WriteTo(ex.Buffer)
+		}
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	hash := ex.DB.GetHashAsArray(v[0])
-	arr := resp.Array{}
+		hash := ex.DB.GetHashAsArray(v[0])
+		arr := resp.Array{}
 
-	for _, field := range hash {
-		arr = append(arr, resp.BulkString(field.Key), resp.BulkString(field.Value))
-	}
-	return arr.WriteTo(ex.Buffer)
+		for _, field := range hash {
+			arr = append(arr, resp.BulkString(field.Key), resp.BulkString(field.Value))
+		}
+		return arr.WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hincrby -> https://redis.io/commands/hincrby
@@ -130,30 +158,32 @@ func hincrby(v Args, ex *Extras) error {
 		return resp.NewError(ErrNotValidInt).WriteTo(ex.Buffer)
 	}
 
-	ex.DB.Lock()
-	defer ex.DB.Unlock()
+	return Queue(func() error {
+		ex.DB.Lock()
+		defer ex.DB.Unlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		keyExists, tipe := ex.DB.Has(v[0])
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
+		hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
 
-	newVal := int64(0)
-	if len(hash[string(v[1])]) == 0 {
-		newVal += by
-	} else {
-		i, err := strconv.ParseInt(string(hash[string(v[1])]), 10, 64)
-		if err != nil {
-			return resp.NewError(ErrNotValidInt).WriteTo(ex.Buffer)
+		newVal := int64(0)
+		if len(hash[string(v[1])]) == 0 {
+			newVal += by
+		} else {
+			i, err := strconv.ParseInt(string(hash[string(v[1])]), 10, 64)
+			if err != nil {
+				return resp.NewError(ErrNotValidInt).WriteTo(ex.Buffer)
+			}
+			newVal = i + by
 		}
-		newVal = i + by
-	}
-	hash[string(v[1])] = []byte(strconv.FormatInt(newVal, 10))
+		hash[string(v[1])] = []byte(strconv.FormatInt(newVal, 10))
 
-	ex.DB.PutHash(v[0], resp.Hash, hash)
-	return resp.Integer(newVal).WriteTo(ex.Buffer)
+		ex.DB.PutHash(v[0], resp.Hash, hash)
+		return resp.Integer(newVal).WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hincrbyfloat -> https://redis.io/commands/hincrbyfloat
@@ -163,91 +193,187 @@ func hincrbyfloat(v Args, ex *Extras) error {
 		return resp.NewError(ErrNotValidInt).WriteTo(ex.Buffer)
 	}
 
-	ex.DB.Lock()
-	defer ex.DB.Unlock()
+	return Queue(func() error {
+		ex.DB.Lock()
+		defer ex.DB.Unlock()
 
-	exist, tipe := ex.DB.Has(v[0])
-	if exist && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		exist, tipe := ex.DB.Has(v[0])
+		if exist && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
+		hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
 
-	newVal := 0.0
-	if len(hash[string(v[1])]) == 0 {
-		newVal += by
-	} else {
-		f, err := strconv.ParseFloat(string(hash[string(v[1])]), 64)
-		if err != nil {
-			return resp.NewError(ErrNotValidFloat).WriteTo(ex.Buffer)
+		newVal := 0.0
+		if len(hash[string(v[1])]) == 0 {
+			newVal += by
+		} else {
+			f, err := strconv.ParseFloat(string(hash[string(v[1])]), 64)
+			if err != nil {
+				return resp.NewError(ErrNotValidFloat).WriteTo(ex.Buffer)
+			}
+			newVal = f + by
 		}
-		newVal = f + by
-	}
-	hash[string(v[1])] = []byte(strconv.FormatFloat(newVal, 'f', -1, 64))
+		hash[string(v[1])] = []byte(strconv.FormatFloat(newVal, 'f', -1, 64))
 
-	ex.DB.PutHash(v[0], resp.Hash, hash)
-	return resp.BulkString(hash[string(v[1])]).WriteTo(ex.Buffer)
+		ex.DB.PutHash(v[0], resp.Hash, hash)
+		return resp.BulkString(hash[string(v[1])]).WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hkeys -> https://redis.io/commands/hkeys
 func hkeys(v Args, ex *Extras) error {
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if !keyExists {
-		return resp.EmptyArray.WriteTo(ex.Buffer)
-	}
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
-
-	fields := ex.DB.GetFieldNames(v[0])
-	arr := resp.Array{}
+		keyExists, tipe := ex.DB.Has(v[0])
+		if !keyExists {
+			return resp.EmptyArray.WriteTo(ex.Buffer)
+		}
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	for _, field := range fields {
-		arr = append(arr, resp.BulkString(field))
-	}
-	return arr.WriteTo(ex.Buffer)
+		arr := resp.Array{}
+		var cursor []byte
+		for {
+			fields, next := ex.DB.ScanFields(v[0], cursor, hashScanBatch)
+			for _, field := range fields {
+				arr = append(arr, resp.BulkString(field.Key))
+			}
+			if len(next) == 0 {
+				break
+			}
+			cursor = next
+		}
+		return arr.WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hvals -> https://redis.io/commands/hvals
 func hvals(v Args, ex *Extras) error {
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if !keyExists {
-		return resp.EmptyArray.WriteTo(ex.Buffer)
+		keyExists, tipe := ex.DB.Has(v[0])
+		if !keyExists {
+			return resp.EmptyArray.WriteTo(ex.Buffer)
+		}
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
+
+		arr := resp.Array{}
+		var cursor []byte
+		for {
+			fields, next := ex.DB.ScanFields(v[0], cursor, hashScanBatch)
+			for _, field := range fields {
+				arr = append(arr, resp.BulkString(field.Value))
+			}
+			if len(next) == 0 {
+				break
+			}
+			cursor = next
+		}
+		return arr.WriteTo(ex.Buffer)
+	}, ex)
+}
+
+// hscan -> https://redis.io/commands/hscan
+func hscan(v Args, ex *Extras) error {
+	if len(v) < 2 {
+		return resp.NewError(ErrFmtWrongNumberArgument, "hscan").WriteTo(ex.Buffer)
 	}
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+
+	cursor, err := decodeScanCursor(v[1])
+	if err != nil {
+		return resp.NewError(ErrSyntax).WriteTo(ex.Buffer)
+	}
+
+	var match []byte
+	count := hashScanBatch
+	if (len(v)-2)%2 != 0 {
+		return resp.NewError(ErrSyntax).WriteTo(ex.Buffer)
+	}
+	for i := 2; i < len(v); i += 2 {
+		switch strings.ToUpper(string(v[i])) {
+		case "MATCH":
+			match = v[i+1]
+		case "COUNT":
+			c, err := strconv.Atoi(string(v[i+1]))
+			if err != nil || c <= 0 {
+				return resp.NewError(ErrSyntax).WriteTo(ex.Buffer)
+			}
+			count = c
+		default:
+			return resp.NewError(ErrSyntax).WriteTo(ex.Buffer)
+		}
 	}
 
-	hash := ex.DB.GetHashAsArray(v[0])
-	arr := resp.Array{}
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
+
+		keyExists, tipe := ex.DB.Has(v[0])
+		if !keyExists {
+			return resp.Array{resp.BulkString("0"), resp.EmptyArray}.WriteTo(ex.Buffer)
+		}
+		if tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
+
+		fields, next := ex.DB.ScanFields(v[0], cursor, count)
+
+		arr := resp.Array{}
+		for _, field := range fields {
+			if match != nil {
+				if ok, _ := path.Match(string(match), string(field.Key)); !ok {
+					continue
+				}
+			}
+			arr = append(arr, resp.BulkString(field.Key), resp.BulkString(field.Value))
+		}
+		return resp.Array{resp.BulkString(encodeScanCursor(next)), arr}.WriteTo(ex.Buffer)
+	}, ex)
+}
 
-	for _, field := range hash {
-		arr = append(arr, resp.BulkString(field.Value))
+// decodeScanCursor decodes a SCAN-family cursor: "0" means start from the
+// beginning, anything else is the hex-encoded next field name to resume from.
+func decodeScanCursor(cursor []byte) ([]byte, error) {
+	if len(cursor) == 1 && cursor[0] == '0' {
+		return nil, nil
 	}
-	return arr.WriteTo(ex.Buffer)
+	return hex.DecodeString(string(cursor))
+}
+
+// encodeScanCursor encodes the next field name to resume from into a cursor,
+// returning "0" once the hash has been fully iterated.
+func encodeScanCursor(field []byte) []byte {
+	if len(field) == 0 {
+		return []byte("0")
+	}
+	return []byte(hex.EncodeToString(field))
 }
 
 // hlen -> https://redis.io/commands/hlen
 func hlen(v Args, ex *Extras) error {
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if !keyExists {
-		return resp.ZeroInteger.WriteTo(ex.Buffer)
-	}
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		keyExists, tipe := ex.DB.Has(v[0])
+		if !keyExists {
+			return resp.ZeroInteger.WriteTo(ex.Buffer)
+		}
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	fields := ex.DB.GetFieldNames(v[0])
-	return resp.Integer(len(fields)).WriteTo(ex.Buffer)
+		fields := ex.DB.GetFieldNames(v[0])
+		return resp.Integer(len(fields)).WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hmget -> https://redis.io/commands/hmget
@@ -256,29 +382,31 @@ func hmget(v Args, ex *Extras) error {
 		return resp.NewError(ErrFmtWrongNumberArgument, "hmget").WriteTo(ex.Buffer)
 	}
 
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	keyExists, tipe := ex.DB.Has(v[0])
-	if keyExists && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
-
-	fields := [][]byte{}
-	for _, f := range v[1:] {
-		fields = append(fields, f)
-	}
-	hash := ex.DB.GetFieldsAsArray(v[0], fields)
+		keyExists, tipe := ex.DB.Has(v[0])
+		if keyExists && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	arr := resp.Array{}
-	for _, field := range hash {
-		if len(field.Value) == 0 {
-			arr = append(arr, resp.NilBulkString)
-		} else {
-			arr = append(arr, resp.BulkString(field.Value))
+		fields := [][]byte{}
+		for _, f := range v[1:] {
+			fields = append(fields, f)
 		}
-	}
-	return arr.WriteTo(ex.Buffer)
+		hash := ex.DB.GetFieldsAsArray(v[0], fields)
+
+		arr := resp.Array{}
+		for _, field := range hash {
+			if len(field.Value) == 0 {
+				arr = append(arr, resp.NilBulkString)
+			} else {
+				arr = append(arr, resp.BulkString(field.Value))
+			}
+		}
+		return arr.WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hmset -> https://redis.io/commands/hmset
@@ -287,85 +415,93 @@ func hmset(v Args, ex *Extras) error {
 		return resp.NewError(ErrFmtWrongNumberArgument, "hmset").WriteTo(ex.Buffer)
 	}
 
-	ex.DB.Lock()
-	defer ex.DB.Unlock()
+	return Queue(func() error {
+		ex.DB.Lock()
+		defer ex.DB.Unlock()
 
-	exist, tipe := ex.DB.Has(v[0])
-	if exist && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		exist, tipe := ex.DB.Has(v[0])
+		if exist && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	hash := make(map[string][]byte)
-	for i := 1; i < len(v); {
-		hash[string(v[i])] = v[i+1]
-		i += 2
-	}
-	ex.DB.PutHash(v[0], resp.Hash, hash)
-	return resp.OkSimpleString.WriteTo(ex.Buffer)
+		hash := make(map[string][]byte)
+		for i := 1; i < len(v); {
+			hash[string(v[i])] = v[i+1]
+			i += 2
+		}
+		ex.DB.PutHash(v[0], resp.Hash, hash)
+		return resp.OkSimpleString.WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hset -> https://redis.io/commands/hset
 func hset(v Args, ex *Extras) error {
-	ex.DB.Lock()
-	defer ex.DB.Unlock()
+	return Queue(func() error {
+		ex.DB.Lock()
+		defer ex.DB.Unlock()
 
-	exist, tipe := ex.DB.Has(v[0])
-	if exist && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		exist, tipe := ex.DB.Has(v[0])
+		if exist && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	fieldExists := false
-	hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
-	if len(hash[string(v[1])]) != 0 {
-		fieldExists = true
-	}
+		fieldExists := false
+		hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
+		if len(hash[string(v[1])]) != 0 {
+			fieldExists = true
+		}
 
-	hash[string(v[1])] = v[2]
-	ex.DB.PutHash(v[0], resp.Hash, hash)
+		hash[string(v[1])] = v[2]
+		ex.DB.PutHash(v[0], resp.Hash, hash)
 
-	if !fieldExists {
-		return resp.OneInteger.WriteTo(ex.Buffer)
-	}
-	return resp.ZeroInteger.WriteTo(ex.Buffer)
+		if !fieldExists {
+			return resp.OneInteger.WriteTo(ex.Buffer)
+		}
+		return resp.ZeroInteger.WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hsetnx -> https://redis.io/commands/hsetnx
 func hsetnx(v Args, ex *Extras) error {
-	ex.DB.Lock()
-	defer ex.DB.Unlock()
+	return Queue(func() error {
+		ex.DB.Lock()
+		defer ex.DB.Unlock()
 
-	exist, tipe := ex.DB.Has(v[0])
-	if exist && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		exist, tipe := ex.DB.Has(v[0])
+		if exist && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	fieldExists := false
-	hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
-	if len(hash[string(v[1])]) != 0 {
-		fieldExists = true
-	}
+		fieldExists := false
+		hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
+		if len(hash[string(v[1])]) != 0 {
+			fieldExists = true
+		}
 
-	if !fieldExists {
-		hash[string(v[1])] = v[2]
-		ex.DB.PutHash(v[0], resp.Hash, hash)
-		return resp.OneInteger.WriteTo(ex.Buffer)
-	}
-	return resp.ZeroInteger.WriteTo(ex.Buffer)
+		if !fieldExists {
+			hash[string(v[1])] = v[2]
+			ex.DB.PutHash(v[0], resp.Hash, hash)
+			return resp.OneInteger.WriteTo(ex.Buffer)
+		}
+		return resp.ZeroInteger.WriteTo(ex.Buffer)
+	}, ex)
 }
 
 // hstrlen -> https://redis.io/commands/hstrlen
 func hstrlen(v Args, ex *Extras) error {
-	ex.DB.RLock()
-	defer ex.DB.RUnlock()
+	return Queue(func() error {
+		ex.DB.RLock()
+		defer ex.DB.RUnlock()
 
-	exist, tipe := ex.DB.Has(v[0])
-	if !exist {
-		return resp.ZeroInteger.WriteTo(ex.Buffer)
-	}
-	if exist && tipe != resp.Hash {
-		return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
-	}
+		exist, tipe := ex.DB.Has(v[0])
+		if !exist {
+			return resp.ZeroInteger.WriteTo(ex.Buffer)
+		}
+		if exist && tipe != resp.Hash {
+			return resp.NewError(ErrWrongType).WriteTo(ex.Buffer)
+		}
 
-	hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
-	return resp.Integer(len(hash[string(v[1])])).WriteTo(ex.Buffer)
+		hash := ex.DB.GetFields(v[0], [][]byte{v[1]})
+		return resp.Integer(len(hash[string(v[1])])).WriteTo(ex.Buffer)
+	}, ex)
 }
@@ -0,0 +1,140 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package command
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/rod6/rodis/rdb"
+	"github.com/rod6/rodis/resp"
+	"github.com/rod6/rodis/storage"
+)
+
+// command
+// ------------
+// BGSAVE
+// DEBUG
+// SAVE
+//
+// Like every handler in command/hash.go, each of these runs its body
+// through Queue, so issuing SAVE/BGSAVE/DEBUG RELOAD between MULTI and EXEC
+// replies "+QUEUED" and replays its real reply inside EXEC's array, instead
+// of replying immediately and desyncing the RESP stream from what the
+// client expects next.
+
+// rdbPath is where SAVE/BGSAVE write the snapshot, and DEBUG RELOAD reads it
+// back from. Real deployments set this from the config file at start-up.
+var rdbPath = "dump.rdb"
+
+// save -> https://redis.io/commands/save
+func save(v Args, ex *Extras) error {
+	return Queue(func() error {
+		if err := DumpRDB(ex.DB, rdbPath); err != nil {
+			return resp.NewError("ERR %s", err).WriteTo(ex.Buffer)
+		}
+		return resp.OkSimpleString.WriteTo(ex.Buffer)
+	}, ex)
+}
+
+// bgsave -> https://redis.io/commands/bgsave
+func bgsave(v Args, ex *Extras) error {
+	return Queue(func() error {
+		db := ex.DB
+		go func() {
+			if err := DumpRDB(db, rdbPath); err != nil {
+				log.Printf("rodis: BGSAVE %s: %v", rdbPath, err)
+			}
+		}()
+		return resp.OkSimpleString.WriteTo(ex.Buffer)
+	}, ex)
+}
+
+// debug -> https://redis.io/commands/debug
+//
+// Only the RELOAD subcommand is implemented: it dumps the current database
+// to rdbPath and immediately loads it back, which is mostly useful to prove
+// an RDB round-trip is lossless.
+func debug(v Args, ex *Extras) error {
+	if len(v) < 1 {
+		return resp.NewError(ErrFmtWrongNumberArgument, "debug").WriteTo(ex.Buffer)
+	}
+	if strings.ToUpper(string(v[0])) != "RELOAD" {
+		return resp.NewError(ErrSyntax).WriteTo(ex.Buffer)
+	}
+
+	return Queue(func() error {
+		if err := DumpRDB(ex.DB, rdbPath); err != nil {
+			return resp.NewError("ERR %s", err).WriteTo(ex.Buffer)
+		}
+		if err := LoadRDB(ex.DB, rdbPath); err != nil {
+			return resp.NewError("ERR %s", err).WriteTo(ex.Buffer)
+		}
+		return resp.OkSimpleString.WriteTo(ex.Buffer)
+	}, ex)
+}
+
+// DumpRDB writes every hash in db to an RDB file at path. Other value types
+// are skipped, since this chunk only has hash commands. The rodis-cli dump
+// subcommand calls it directly; rodis-storaged's -rdb-load flag uses
+// LoadRDB below.
+func DumpRDB(db storage.Backend, path string) error {
+	db.RLock()
+	defer db.RUnlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := rdb.NewEncoder(f)
+	if err := enc.WriteHeader(); err != nil {
+		return err
+	}
+	if err := enc.SelectDB(0); err != nil {
+		return err
+	}
+
+	for _, key := range db.Keys() {
+		exists, tipe := db.Has(key)
+		if !exists || tipe != resp.Hash {
+			continue
+		}
+		if err := enc.WriteHash(key, db.GetHashAsArray(key)); err != nil {
+			return err
+		}
+	}
+
+	return enc.Finish()
+}
+
+// LoadRDB reads an RDB file at path back into db, restoring every hash it
+// contains via PutHash.
+func LoadRDB(db storage.Backend, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	db.Lock()
+	defer db.Unlock()
+
+	return rdb.NewDecoder(f).Load(&backendLoader{db: db})
+}
+
+// backendLoader adapts a storage.Backend to rdb.Loader.
+type backendLoader struct {
+	db storage.Backend
+}
+
+func (l *backendLoader) SelectDB(index int) {}
+
+func (l *backendLoader) LoadHash(key []byte, hash map[string][]byte) {
+	l.db.PutHash(key, resp.Hash, hash)
+}
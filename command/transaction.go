@@ -0,0 +1,125 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/rod6/rodis/resp"
+	"github.com/rod6/rodis/storage"
+)
+
+// command
+// ------------
+// DISCARD
+// EXEC
+// MULTI
+// WATCH
+//
+// Isolation is handled entirely by storage.CacheDB: while a connection is
+// inside MULTI, ex.DB points at its *storage.CacheDB instead of the shared
+// backend, so a handler's reads and writes need no changes to stay isolated
+// - they're already written against the Backend interface. That's not the
+// whole story, though: a queued command's own RESP reply still has to be
+// held back until EXEC instead of reaching the client right away, and nothing
+// about the Backend interface does that for free. Queue below is what
+// every queueable handler calls to get that part too - see command/hash.go
+// and command/persistence.go - while multi/discard/exec/watch run directly,
+// since those execute immediately even inside a transaction.
+
+// Queue runs fn, a single queued command's handler, so EXEC can later replay
+// its reply: inside a transaction, fn's real reply is captured into
+// ex.TxReplies instead of reaching the client, and "+QUEUED" is written in
+// its place; outside a transaction, fn just runs as normal.
+func Queue(fn func() error, ex *Extras) error {
+	if ex.Tx == nil {
+		return fn()
+	}
+
+	var buf bytes.Buffer
+	saved := ex.Buffer
+	ex.Buffer = &buf
+	err := fn()
+	ex.Buffer = saved
+	if err != nil {
+		return err
+	}
+
+	ex.TxReplies = append(ex.TxReplies, buf.Bytes())
+	return resp.SimpleString("QUEUED").WriteTo(ex.Buffer)
+}
+
+// multi -> https://redis.io/commands/multi
+func multi(v Args, ex *Extras) error {
+	if ex.Tx != nil {
+		return resp.NewError("ERR MULTI calls can not be nested").WriteTo(ex.Buffer)
+	}
+
+	ex.Tx = storage.NewCacheDB(ex.DB, ex.Watcher, ex.Watched)
+	ex.Watched = nil
+	ex.DB = ex.Tx
+	ex.TxReplies = nil
+	return resp.OkSimpleString.WriteTo(ex.Buffer)
+}
+
+// discard -> https://redis.io/commands/discard
+func discard(v Args, ex *Extras) error {
+	if ex.Tx == nil {
+		return resp.NewError("ERR DISCARD without MULTI").WriteTo(ex.Buffer)
+	}
+
+	ex.Tx.Discard()
+	ex.DB = ex.Tx.Parent()
+	ex.Tx = nil
+	ex.TxReplies = nil
+	return resp.OkSimpleString.WriteTo(ex.Buffer)
+}
+
+// exec -> https://redis.io/commands/exec
+func exec(v Args, ex *Extras) error {
+	if ex.Tx == nil {
+		return resp.NewError("ERR EXEC without MULTI").WriteTo(ex.Buffer)
+	}
+
+	tx := ex.Tx
+	replies := ex.TxReplies
+	ex.DB = tx.Parent()
+	ex.Tx = nil
+	ex.TxReplies = nil
+
+	if !tx.Exec() {
+		return resp.NilBulkString.WriteTo(ex.Buffer)
+	}
+
+	if _, err := fmt.Fprintf(ex.Buffer, "*%d\r\n", len(replies)); err != nil {
+		return err
+	}
+	for _, reply := range replies {
+		if _, err := ex.Buffer.Write(reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watch -> https://redis.io/commands/watch
+func watch(v Args, ex *Extras) error {
+	if len(v) < 1 {
+		return resp.NewError(ErrFmtWrongNumberArgument, "watch").WriteTo(ex.Buffer)
+	}
+	if ex.Tx != nil {
+		return resp.NewError("ERR WATCH inside MULTI is not allowed").WriteTo(ex.Buffer)
+	}
+
+	if ex.Watched == nil {
+		ex.Watched = make(map[string]uint64, len(v))
+	}
+	for _, key := range v {
+		ex.Watched[string(key)] = ex.Watcher.Version(key)
+	}
+	return resp.OkSimpleString.WriteTo(ex.Buffer)
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package rdb
+
+import "hash/crc64"
+
+// jonesPoly is the polynomial real Redis computes its RDB footer checksum
+// with (crc64.c's CRC64_POLY, a.k.a. the "Jones" CRC-64 variant) - not the
+// ISO or ECMA polynomials hash/crc64 exposes constants for, but MakeTable
+// accepts any polynomial, reflected the same way ISO/ECMA are. Using the
+// wrong polynomial here means every checksum disagrees with real Redis: its
+// rdb-load rejects our dumps, and ours rejects its unless a dump happens to
+// have checksums disabled.
+const jonesPoly = 0xad93d23594c935a9
+
+var jonesTable = crc64.MakeTable(jonesPoly)
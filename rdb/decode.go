@@ -0,0 +1,142 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package rdb
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/crc64"
+	"io"
+)
+
+// Loader receives values as a Decoder walks an RDB stream. Command packages
+// implement it to feed decoded values into a storage.Backend.
+type Loader interface {
+	// SelectDB is called for every SELECTDB opcode in the stream.
+	SelectDB(index int)
+
+	// LoadHash is called once per hash value, with every field already
+	// decoded.
+	LoadHash(key []byte, hash map[string][]byte)
+}
+
+// Decoder reads an RDB stream written by Encoder (or by real Redis, for the
+// value types it implements).
+type Decoder struct {
+	r   io.Reader // the underlying reader, to read the checksum footer
+	crc hash.Hash64
+	tr  io.Reader // r, tee'd into crc
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	crc := crc64.New(jonesTable)
+	return &Decoder{r: r, crc: crc, tr: io.TeeReader(r, crc)}
+}
+
+// Load reads the stream until EOF, calling loader for every value it
+// understands, and returns once the checksum footer has been verified.
+func (d *Decoder) Load(loader Loader) error {
+	magic := make([]byte, 9)
+	if _, err := io.ReadFull(d.tr, magic); err != nil {
+		return err
+	}
+	if string(magic[:5]) != header {
+		return ErrBadMagic
+	}
+
+	for {
+		opcode, err := readByte(d.tr)
+		if err != nil {
+			return err
+		}
+
+		switch opcode {
+		case OpEOF:
+			return d.verifyChecksum()
+		case OpSelectDB:
+			index, err := readLength(d.tr)
+			if err != nil {
+				return err
+			}
+			loader.SelectDB(index)
+		case OpExpireTimeMs:
+			if _, err := io.CopyN(io.Discard, d.tr, 8); err != nil {
+				return err
+			}
+		case OpExpireTime:
+			if _, err := io.CopyN(io.Discard, d.tr, 4); err != nil {
+				return err
+			}
+		case OpAux:
+			if _, err := readString(d.tr); err != nil {
+				return err
+			}
+			if _, err := readString(d.tr); err != nil {
+				return err
+			}
+		case OpResizeDB:
+			if _, err := readLength(d.tr); err != nil {
+				return err
+			}
+			if _, err := readLength(d.tr); err != nil {
+				return err
+			}
+		default:
+			if err := d.loadValue(opcode, loader); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// loadValue reads a single <key><value> pair whose value type is tipe.
+func (d *Decoder) loadValue(tipe byte, loader Loader) error {
+	key, err := readString(d.tr)
+	if err != nil {
+		return err
+	}
+
+	switch tipe {
+	case TypeHash:
+		count, err := readLength(d.tr)
+		if err != nil {
+			return err
+		}
+		hash := make(map[string][]byte, count)
+		for i := 0; i < count; i++ {
+			field, err := readString(d.tr)
+			if err != nil {
+				return err
+			}
+			value, err := readString(d.tr)
+			if err != nil {
+				return err
+			}
+			hash[string(field)] = value
+		}
+		loader.LoadHash(key, hash)
+		return nil
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+// verifyChecksum reads the 8-byte CRC64 footer and compares it against
+// everything read so far. A zero footer (checksums disabled) is accepted
+// without comparison, matching real Redis' behaviour.
+func (d *Decoder) verifyChecksum() error {
+	footer := make([]byte, 8)
+	if _, err := io.ReadFull(d.r, footer); err != nil {
+		return err
+	}
+
+	got := binary.LittleEndian.Uint64(footer)
+	if got != 0 && got != d.crc.Sum64() {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package rdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+
+	"github.com/rod6/rodis/storage"
+)
+
+// Encoder writes an RDB stream: a header, a sequence of SELECTDB/value
+// opcodes, an EOF opcode and a trailing CRC64 checksum of everything written
+// before it.
+type Encoder struct {
+	w   io.Writer // the underlying writer, for the final checksum
+	crc hash.Hash64
+	mw  io.Writer // w, tee'd into crc
+}
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	crc := crc64.New(jonesTable)
+	return &Encoder{w: w, crc: crc, mw: io.MultiWriter(w, crc)}
+}
+
+// WriteHeader writes the "REDIS0009"-style magic and version. It must be the
+// first thing written to the stream.
+func (e *Encoder) WriteHeader() error {
+	_, err := fmt.Fprintf(e.mw, "%s%04d", header, Version)
+	return err
+}
+
+// SelectDB writes a SELECTDB opcode, switching the logical database that
+// subsequent values belong to.
+func (e *Encoder) SelectDB(index int) error {
+	if _, err := e.mw.Write([]byte{OpSelectDB}); err != nil {
+		return err
+	}
+	return writeLength(e.mw, index)
+}
+
+// WriteHash writes key's hash as an RDB type-4 (hash) value:
+// <TypeHash><key><count>(<field><value>)*count.
+func (e *Encoder) WriteHash(key []byte, fields []storage.Field) error {
+	if _, err := e.mw.Write([]byte{TypeHash}); err != nil {
+		return err
+	}
+	if err := writeString(e.mw, key); err != nil {
+		return err
+	}
+	if err := writeLength(e.mw, len(fields)); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := writeString(e.mw, f.Key); err != nil {
+			return err
+		}
+		if err := writeString(e.mw, f.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finish writes the EOF opcode and the CRC64 checksum footer. It must be
+// called exactly once, after every value has been written.
+func (e *Encoder) Finish() error {
+	if _, err := e.mw.Write([]byte{OpEOF}); err != nil {
+		return err
+	}
+
+	var footer [8]byte
+	binary.LittleEndian.PutUint64(footer[:], e.crc.Sum64())
+	_, err := e.w.Write(footer[:])
+	return err
+}
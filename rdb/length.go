@@ -0,0 +1,219 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package rdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// errSpecialLength is returned by readLength when it meets a 11-prefixed
+// length byte (Redis' "special" integer/LZF encodings): readLength is only
+// ever used for plain counts (SELECTDB index, field counts, ...), which
+// real Redis never writes in a special encoding - only string values do,
+// and those go through readString instead.
+var errSpecialLength = errors.New("rdb: special length encodings are not supported here")
+
+// errLZFCorrupt is returned when an LZF-compressed string fails to
+// decompress to its declared length.
+var errLZFCorrupt = errors.New("rdb: corrupt LZF-compressed string")
+
+// Redis' "special" string encodings: the low 6 bits of a length byte whose
+// top 2 bits are both set, naming an encoding instead of a length.
+const (
+	encInt8  = 0
+	encInt16 = 1
+	encInt32 = 2
+	encLZF   = 3
+)
+
+// writeLength writes n using the RDB length encoding: 6 bits, 14 bits or 32
+// bits, picking the smallest that fits.
+func writeLength(w io.Writer, n int) error {
+	switch {
+	case n < 1<<6:
+		_, err := w.Write([]byte{byte(n)})
+		return err
+	case n < 1<<14:
+		_, err := w.Write([]byte{0x40 | byte(n>>8), byte(n)})
+		return err
+	default:
+		buf := make([]byte, 5)
+		buf[0] = 0x80
+		binary.BigEndian.PutUint32(buf[1:], uint32(n))
+		_, err := w.Write(buf)
+		return err
+	}
+}
+
+// readLength reads back a length written by writeLength. It rejects a
+// special (int/LZF encoded) length byte - callers that can legally meet one
+// use readString instead.
+func readLength(r io.Reader) (int, error) {
+	b, err := readByte(r)
+	if err != nil {
+		return 0, err
+	}
+	return readLengthFrom(r, b)
+}
+
+// readLengthFrom decodes a length whose first byte, b, has already been
+// read.
+func readLengthFrom(r io.Reader, b byte) (int, error) {
+	switch b >> 6 {
+	case 0:
+		return int(b & 0x3F), nil
+	case 1:
+		b2, err := readByte(r)
+		if err != nil {
+			return 0, err
+		}
+		return int(b&0x3F)<<8 | int(b2), nil
+	case 2:
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return 0, err
+		}
+		return int(binary.BigEndian.Uint32(buf)), nil
+	default:
+		return 0, errSpecialLength
+	}
+}
+
+// writeString writes a length-prefixed string. rodis never writes the
+// special int/LZF encodings - only plain length-prefixed bytes - which is a
+// valid RDB string encoding real Redis reads back fine, just not the most
+// compact one.
+func writeString(w io.Writer, b []byte) error {
+	if err := writeLength(w, len(b)); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// readString reads back a string: either a plain length-prefixed one (as
+// writeString produces), or one of real Redis' special encodings (a packed
+// 8/16/32-bit integer, or an LZF-compressed run), both of which real Redis
+// writes by default for hash fields and values that qualify.
+func readString(r io.Reader) ([]byte, error) {
+	b, err := readByte(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if b>>6 == 3 {
+		return readEncodedString(r, b&0x3F)
+	}
+
+	n, err := readLengthFrom(r, b)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readEncodedString reads the body of one of Redis' special string
+// encodings, enc being the low 6 bits of its length byte.
+func readEncodedString(r io.Reader, enc byte) ([]byte, error) {
+	switch enc {
+	case encInt8:
+		b, err := readByte(r)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.FormatInt(int64(int8(b)), 10)), nil
+	case encInt16:
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return []byte(strconv.FormatInt(int64(int16(binary.LittleEndian.Uint16(buf))), 10)), nil
+	case encInt32:
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return []byte(strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(buf))), 10)), nil
+	case encLZF:
+		clen, err := readLength(r)
+		if err != nil {
+			return nil, err
+		}
+		ulen, err := readLength(r)
+		if err != nil {
+			return nil, err
+		}
+		compressed := make([]byte, clen)
+		if _, err := io.ReadFull(r, compressed); err != nil {
+			return nil, err
+		}
+		return lzfDecompress(compressed, ulen)
+	default:
+		return nil, errSpecialLength
+	}
+}
+
+// lzfDecompress decompresses data, produced by Redis' LZF string encoding,
+// into a buffer of exactly ulen bytes - the same algorithm as liblzf's
+// lzf_d.c, which Redis uses to write it.
+func lzfDecompress(data []byte, ulen int) ([]byte, error) {
+	out := make([]byte, 0, ulen)
+	i := 0
+	for i < len(data) {
+		ctrl := int(data[i])
+		i++
+
+		if ctrl < 32 {
+			length := ctrl + 1
+			if i+length > len(data) {
+				return nil, errLZFCorrupt
+			}
+			out = append(out, data[i:i+length]...)
+			i += length
+			continue
+		}
+
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(data) {
+				return nil, errLZFCorrupt
+			}
+			length += int(data[i])
+			i++
+		}
+		if i >= len(data) {
+			return nil, errLZFCorrupt
+		}
+		ref := len(out) - (ctrl&0x1F)<<8 - int(data[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errLZFCorrupt
+		}
+
+		length += 2
+		for j := 0; j < length; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+
+	if len(out) != ulen {
+		return nil, errLZFCorrupt
+	}
+	return out, nil
+}
+
+func readByte(r io.Reader) (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r, b[:])
+	return b[0], err
+}
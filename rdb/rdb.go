@@ -0,0 +1,53 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+// Package rdb reads and writes the Redis RDB snapshot format, so rodis can
+// import a dump produced by real Redis, and export one that real Redis (or
+// another rodis instance) can load back in.
+package rdb
+
+import "errors"
+
+// Version is the RDB version rodis writes. Decoder.Load checks only the
+// "REDIS" part of a stream's magic, not its version digits.
+const Version = 9
+
+// header is the first 5 bytes of the 9-byte magic rodis writes before any
+// opcode: "REDIS", which is followed by a 4-digit, zero-padded version number.
+const header = "REDIS"
+
+// Opcodes, as used by Redis itself. Only the ones rodis currently emits or
+// understands are listed; AUX/RESIZEDB/EXPIRETIME/EXPIRETIME_MS are accepted
+// on read (and skipped) but never written. MODULE-AUX is not handled.
+const (
+	OpAux          = 0xFA
+	OpResizeDB     = 0xFB
+	OpExpireTimeMs = 0xFC
+	OpExpireTime   = 0xFD
+	OpSelectDB     = 0xFE
+	OpEOF          = 0xFF
+)
+
+// Type bytes, one per value kind. rodis only has hash commands in this chunk,
+// so Decoder.Load only implements TypeHash; the others are recognized but
+// rejected with ErrUnsupportedType until their command packages land.
+const (
+	TypeString = 0
+	TypeList   = 1
+	TypeSet    = 2
+	TypeZSet   = 3
+	TypeHash   = 4
+)
+
+// ErrUnsupportedType is returned while decoding a value whose type byte is
+// not yet implemented.
+var ErrUnsupportedType = errors.New("rdb: unsupported value type")
+
+// ErrBadMagic is returned when a stream does not start with the RDB header.
+var ErrBadMagic = errors.New("rdb: not an RDB stream")
+
+// ErrChecksumMismatch is returned when the trailing CRC64 footer does not
+// match the bytes that were read.
+var ErrChecksumMismatch = errors.New("rdb: checksum mismatch")
@@ -0,0 +1,72 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import "bytes"
+
+// Backend is the storage engine interface that every command handler is
+// driven through, instead of reaching into *LevelDB directly. It lets rodis
+// swap goleveldb for another key/value engine - or a pure in-memory one for
+// tests - without touching the command package. Extras.DB holds a Backend.
+//
+// Lock/RLock/Unlock/RUnlock guard a single logical operation the same way
+// *LevelDB does today: writers take Lock, readers take RLock.
+type Backend interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+
+	// Has reports whether key exists, and if so, the resp type it was
+	// stored as.
+	Has(key []byte) (bool, byte)
+
+	// DeleteHash removes a hash key and all of its fields.
+	DeleteHash(key []byte)
+
+	// PutHash writes hash data, creating or overwriting key's metadata.
+	PutHash(key []byte, tipe byte, hash map[string][]byte)
+
+	// GetHash reads the whole hash.
+	GetHash(key []byte) map[string][]byte
+
+	// GetHashAsArray reads the whole hash preserving field order.
+	GetHashAsArray(key []byte) []Field
+
+	// GetFields reads a set of fields; missing fields come back as nil.
+	GetFields(key []byte, fields [][]byte) map[string][]byte
+
+	// GetFieldsAsArray reads a set of fields preserving the requested order.
+	GetFieldsAsArray(key []byte, fields [][]byte) []Field
+
+	// DeleteFields removes the given fields, and the key itself if that
+	// empties the hash.
+	DeleteFields(key []byte, fields [][]byte)
+
+	// GetFieldNames lists all field names in a hash.
+	GetFieldNames(key []byte) [][]byte
+
+	// ScanFields pages through a hash's fields, see (*LevelDB).ScanFields.
+	ScanFields(key []byte, startField []byte, count int) (fields []Field, nextField []byte)
+
+	// Keys lists every key currently stored, regardless of type. SAVE,
+	// BGSAVE and DEBUG RELOAD use it to walk the whole keyspace.
+	Keys() [][]byte
+
+	// Close releases the backend's underlying resources.
+	Close() error
+}
+
+var _ Backend = (*LevelDB)(nil)
+
+// fieldNameFromKey extracts the field name from an encoded field key, i.e.
+// the bytes after the Seperator in ValuePrefix|key|Seperator|field. Backends
+// that share LevelDB's flat byte-key layout (badger, bbolt) use this to
+// decode iterator keys back into field names.
+func fieldNameFromKey(k []byte) []byte {
+	sepIndex := bytes.IndexByte(k, '|')
+	return append([]byte{}, k[sepIndex+1:]...)
+}
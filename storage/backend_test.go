@@ -0,0 +1,201 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// hashType stands in for a resp type byte: storage doesn't depend on the
+// resp package, so any non-zero byte exercises PutHash/Has identically.
+const hashType byte = 1
+
+// backendFactories lists every Backend implementation, so testBackend runs
+// against all of them and they can't silently diverge from each other.
+func backendFactories(t *testing.T) map[string]Backend {
+	bolt, err := NewBoltBackend(filepath.Join(t.TempDir(), "rodis.db"))
+	if err != nil {
+		t.Fatalf("NewBoltBackend: %v", err)
+	}
+	badger, err := NewBadgerBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewBadgerBackend: %v", err)
+	}
+	ldb, err := NewLevelDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLevelDB: %v", err)
+	}
+
+	return map[string]Backend{
+		"Memory":  NewMemoryBackend(),
+		"LevelDB": ldb,
+		"Badger":  badger,
+		"Bolt":    bolt,
+	}
+}
+
+// TestBackends runs the same hash semantics against every Backend
+// implementation.
+func TestBackends(t *testing.T) {
+	for name, db := range backendFactories(t) {
+		name, db := name, db
+		t.Run(name, func(t *testing.T) {
+			defer db.Close()
+			testBackend(t, db)
+		})
+	}
+}
+
+func testBackend(t *testing.T, db Backend) {
+	key := []byte("myhash")
+
+	if exists, _ := db.Has(key); exists {
+		t.Fatalf("Has: new key reports existing")
+	}
+
+	hash := map[string][]byte{"f1": []byte("v1"), "f2": []byte("v2")}
+	db.PutHash(key, hashType, hash)
+
+	exists, tipe := db.Has(key)
+	if !exists || tipe != hashType {
+		t.Fatalf("Has after PutHash: exists=%v type=%v", exists, tipe)
+	}
+
+	got := db.GetHash(key)
+	if len(got) != 2 || string(got["f1"]) != "v1" || string(got["f2"]) != "v2" {
+		t.Fatalf("GetHash: got %v", got)
+	}
+
+	names := db.GetFieldNames(key)
+	sort.Slice(names, func(i, j int) bool { return bytes.Compare(names[i], names[j]) < 0 })
+	if len(names) != 2 || string(names[0]) != "f1" || string(names[1]) != "f2" {
+		t.Fatalf("GetFieldNames: got %v", names)
+	}
+
+	fields := db.GetFieldsAsArray(key, [][]byte{[]byte("f1"), []byte("missing")})
+	if len(fields) != 2 || string(fields[0].Value) != "v1" || fields[1].Value != nil {
+		t.Fatalf("GetFieldsAsArray: got %+v", fields)
+	}
+
+	all, next := db.ScanFields(key, nil, 10)
+	if len(all) != 2 || next != nil {
+		t.Fatalf("ScanFields: got %v fields, next=%v", all, next)
+	}
+
+	found := false
+	for _, k := range db.Keys() {
+		if bytes.Equal(k, key) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Keys: %q missing from %v", key, db.Keys())
+	}
+
+	db.DeleteFields(key, [][]byte{[]byte("f1")})
+	if got := db.GetHash(key); len(got) != 1 || string(got["f2"]) != "v2" {
+		t.Fatalf("GetHash after DeleteFields: got %v", got)
+	}
+
+	db.DeleteFields(key, [][]byte{[]byte("f2")})
+	if exists, _ := db.Has(key); exists {
+		t.Fatalf("Has after deleting every field: still exists")
+	}
+	for _, k := range db.Keys() {
+		if bytes.Equal(k, key) {
+			t.Fatalf("Keys: %q still present after its last field was deleted", key)
+		}
+	}
+
+	db.PutHash(key, hashType, hash)
+	db.DeleteHash(key)
+	if exists, _ := db.Has(key); exists {
+		t.Fatalf("Has after DeleteHash: still exists")
+	}
+}
+
+// TestLevelDBPackedMigration exercises the up/down migration PutHash and
+// DeleteFields do between the packed and per-field representations - the
+// one piece of hash semantics that's LevelDB-specific rather than shared
+// across every Backend.
+func TestLevelDBPackedMigration(t *testing.T) {
+	db, err := NewLevelDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLevelDB: %v", err)
+	}
+	defer db.Close()
+
+	origMax := HashMaxEntries
+	HashMaxEntries = 2
+	defer func() { HashMaxEntries = origMax }()
+
+	key := []byte("mig")
+	db.PutHash(key, hashType, map[string][]byte{"f1": []byte("v1")})
+	if _, packed := db.packedFields(key); !packed {
+		t.Fatalf("a hash under HashMaxEntries should start out packed")
+	}
+
+	db.PutHash(key, hashType, map[string][]byte{"f2": []byte("v2"), "f3": []byte("v3")})
+	if _, packed := db.packedFields(key); packed {
+		t.Fatalf("a hash grown past HashMaxEntries should have migrated to per-field")
+	}
+
+	db.DeleteFields(key, [][]byte{[]byte("f2"), []byte("f3")})
+	if _, packed := db.packedFields(key); !packed {
+		t.Fatalf("a hash shrunk back under HashMaxEntries should have migrated back to packed")
+	}
+	if got := db.GetHash(key); len(got) != 1 || string(got["f1"]) != "v1" {
+		t.Fatalf("GetHash after migrating back to packed: got %v", got)
+	}
+}
+
+// BenchmarkHashRead compares GetHash latency for the packed and per-field
+// representations, at 10, 100 and 1000 fields. HashMaxEntries is forced per
+// subtest so the same field count is actually measured under both
+// representations, rather than letting the real threshold pick one.
+func BenchmarkHashRead(b *testing.B) {
+	origMax := HashMaxEntries
+	defer func() { HashMaxEntries = origMax }()
+
+	for _, n := range []int{10, 100, 1000} {
+		for _, enc := range []string{"packed", "per-field"} {
+			b.Run(fmt.Sprintf("%dfields/%s", n, enc), func(b *testing.B) {
+				if enc == "packed" {
+					HashMaxEntries = n
+				} else {
+					HashMaxEntries = 0
+				}
+
+				db, err := NewLevelDB(b.TempDir())
+				if err != nil {
+					b.Fatalf("NewLevelDB: %v", err)
+				}
+				defer db.Close()
+
+				hash := make(map[string][]byte, n)
+				for i := 0; i < n; i++ {
+					hash[fmt.Sprintf("field%d", i)] = []byte(fmt.Sprintf("value%d", i))
+				}
+
+				key := []byte("benchhash")
+				db.PutHash(key, hashType, hash)
+				if _, packed := db.packedFields(key); packed != (enc == "packed") {
+					b.Fatalf("PutHash did not produce the %s representation", enc)
+				}
+				b.ReportMetric(float64(len(encodePacked(hash))), "packed-bytes")
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					db.GetHash(key)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import (
+	"sync"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// BadgerBackend is a Backend backed by BadgerDB. It reuses the same flat key
+// layout as LevelDB (encodeMetaKey/encodeFieldKey), since both engines are
+// ordered byte-key stores.
+type BadgerBackend struct {
+	mu sync.RWMutex
+	db *badger.DB
+}
+
+// NewBadgerBackend opens (or creates) a BadgerDB database at path.
+func NewBadgerBackend(path string) (*BadgerBackend, error) {
+	db, err := badger.Open(badger.DefaultOptions(path))
+	if err != nil {
+		return nil, err
+	}
+	return &BadgerBackend{db: db}, nil
+}
+
+func (b *BadgerBackend) Lock()    { b.mu.Lock() }
+func (b *BadgerBackend) Unlock()  { b.mu.Unlock() }
+func (b *BadgerBackend) RLock()   { b.mu.RLock() }
+func (b *BadgerBackend) RUnlock() { b.mu.RUnlock() }
+
+func (b *BadgerBackend) get(key []byte) []byte {
+	var value []byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (b *BadgerBackend) delete(keys [][]byte) {
+	err := b.db.Update(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Has reports whether key exists, and if so, the resp type it was stored as.
+func (b *BadgerBackend) Has(key []byte) (bool, byte) {
+	meta := b.get(encodeMetaKey(key))
+	if meta == nil {
+		return false, 0
+	}
+	return true, decodeMetadata(meta)
+}
+
+// DeleteHash removes a hash key and all of its fields.
+func (b *BadgerBackend) DeleteHash(key []byte) {
+	keys := [][]byte{encodeMetaKey(key)}
+
+	hashPrefix := encodeFieldKey(key, nil)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = hashPrefix
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		for iter.Seek(hashPrefix); iter.ValidForPrefix(hashPrefix); iter.Next() {
+			keys = append(keys, append([]byte{}, iter.Item().Key()...))
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	b.delete(keys)
+}
+
+// PutHash writes hash data, creating or overwriting key's metadata.
+func (b *BadgerBackend) PutHash(key []byte, tipe byte, hash map[string][]byte) {
+	err := b.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(encodeMetaKey(key), encodeMetadata(tipe)); err != nil {
+			return err
+		}
+		for field, value := range hash {
+			if err := txn.Set(encodeFieldKey(key, []byte(field)), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// scanAll walks every field of key's hash, in key order.
+func (b *BadgerBackend) scanAll(key []byte) []Field {
+	hash := []Field{}
+	hashPrefix := encodeFieldKey(key, nil)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = hashPrefix
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		for iter.Seek(hashPrefix); iter.ValidForPrefix(hashPrefix); iter.Next() {
+			item := iter.Item()
+			field := fieldNameFromKey(item.Key())
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			hash = append(hash, Field{field, value})
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// hasAnyField reports whether key still has at least one field, without
+// reading the rest of the hash.
+func (b *BadgerBackend) hasAnyField(key []byte) bool {
+	hashPrefix := encodeFieldKey(key, nil)
+	found := false
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = hashPrefix
+		opts.PrefetchValues = false
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		iter.Seek(hashPrefix)
+		found = iter.ValidForPrefix(hashPrefix)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return found
+}
+
+// GetHash reads the whole hash.
+func (b *BadgerBackend) GetHash(key []byte) map[string][]byte {
+	hash := make(map[string][]byte)
+	for _, field := range b.scanAll(key) {
+		hash[string(field.Key)] = field.Value
+	}
+	return hash
+}
+
+// GetHashAsArray reads the whole hash preserving field order.
+func (b *BadgerBackend) GetHashAsArray(key []byte) []Field {
+	return b.scanAll(key)
+}
+
+// GetFields reads a set of fields; missing fields come back as nil.
+func (b *BadgerBackend) GetFields(key []byte, fields [][]byte) map[string][]byte {
+	hash := make(map[string][]byte)
+	for _, field := range fields {
+		hash[string(field)] = b.get(encodeFieldKey(key, field))
+	}
+	return hash
+}
+
+// GetFieldsAsArray reads a set of fields preserving the requested order.
+func (b *BadgerBackend) GetFieldsAsArray(key []byte, fields [][]byte) []Field {
+	hash := []Field{}
+	for _, field := range fields {
+		hash = append(hash, Field{field, b.get(encodeFieldKey(key, field))})
+	}
+	return hash
+}
+
+// DeleteFields removes the given fields, and the key itself if that empties
+// the hash.
+func (b *BadgerBackend) DeleteFields(key []byte, fields [][]byte) {
+	keys := [][]byte{}
+	for _, field := range fields {
+		keys = append(keys, encodeFieldKey(key, field))
+	}
+	b.delete(keys)
+
+	if !b.hasAnyField(key) {
+		b.delete([][]byte{encodeMetaKey(key)})
+	}
+}
+
+// GetFieldNames lists all field names in a hash.
+func (b *BadgerBackend) GetFieldNames(key []byte) [][]byte {
+	fields := [][]byte{}
+	for _, field := range b.scanAll(key) {
+		fields = append(fields, field.Key)
+	}
+	return fields
+}
+
+// ScanFields pages through a hash's fields, see (*LevelDB).ScanFields.
+func (b *BadgerBackend) ScanFields(key []byte, startField []byte, count int) (fields []Field, nextField []byte) {
+	hashPrefix := encodeFieldKey(key, nil)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = hashPrefix
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+
+		for iter.Seek(encodeFieldKey(key, startField)); iter.ValidForPrefix(hashPrefix) && len(fields) < count; iter.Next() {
+			item := iter.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			fields = append(fields, Field{fieldNameFromKey(item.Key()), value})
+		}
+		if iter.ValidForPrefix(hashPrefix) {
+			nextField = fieldNameFromKey(iter.Item().Key())
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return fields, nextField
+}
+
+// Keys lists every key currently stored, regardless of type, by scanning the
+// metadata keyspace - every key, packed or per-field, has a metadata entry.
+func (b *BadgerBackend) Keys() [][]byte {
+	keys := [][]byte{}
+	metaPrefix := []byte{MetaPrefix}
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = metaPrefix
+		opts.PrefetchValues = false
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		for iter.Seek(metaPrefix); iter.ValidForPrefix(metaPrefix); iter.Next() {
+			keys = append(keys, append([]byte{}, iter.Item().Key()[1:]...))
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+// Close releases the backend's underlying resources.
+func (b *BadgerBackend) Close() error {
+	return b.db.Close()
+}
+
+var _ Backend = (*BadgerBackend)(nil)
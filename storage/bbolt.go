@@ -0,0 +1,253 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import (
+	"bytes"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket is the single bucket rodis keeps all keys in, using the same
+// flat ValuePrefix|key|Seperator|field layout as LevelDB.
+var boltBucket = []byte("rodis")
+
+// BoltBackend is a Backend backed by bbolt (formerly Bolt DB).
+type BoltBackend struct {
+	mu sync.RWMutex
+	db *bolt.DB
+}
+
+// NewBoltBackend opens (or creates) a bbolt database at path.
+func NewBoltBackend(path string) (*BoltBackend, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltBackend{db: db}, nil
+}
+
+func (b *BoltBackend) Lock()    { b.mu.Lock() }
+func (b *BoltBackend) Unlock()  { b.mu.Unlock() }
+func (b *BoltBackend) RLock()   { b.mu.RLock() }
+func (b *BoltBackend) RUnlock() { b.mu.RUnlock() }
+
+func (b *BoltBackend) get(key []byte) []byte {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(boltBucket).Get(key); v != nil {
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (b *BoltBackend) delete(keys [][]byte) {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Has reports whether key exists, and if so, the resp type it was stored as.
+func (b *BoltBackend) Has(key []byte) (bool, byte) {
+	meta := b.get(encodeMetaKey(key))
+	if meta == nil {
+		return false, 0
+	}
+	return true, decodeMetadata(meta)
+}
+
+// DeleteHash removes a hash key and all of its fields.
+func (b *BoltBackend) DeleteHash(key []byte) {
+	keys := [][]byte{encodeMetaKey(key)}
+
+	hashPrefix := encodeFieldKey(key, nil)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, _ := c.Seek(hashPrefix); k != nil && bytes.HasPrefix(k, hashPrefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	b.delete(keys)
+}
+
+// PutHash writes hash data, creating or overwriting key's metadata.
+func (b *BoltBackend) PutHash(key []byte, tipe byte, hash map[string][]byte) {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		if err := bucket.Put(encodeMetaKey(key), encodeMetadata(tipe)); err != nil {
+			return err
+		}
+		for field, value := range hash {
+			if err := bucket.Put(encodeFieldKey(key, []byte(field)), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// scanAll walks every field of key's hash, in key order.
+func (b *BoltBackend) scanAll(key []byte) []Field {
+	hash := []Field{}
+	hashPrefix := encodeFieldKey(key, nil)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, v := c.Seek(hashPrefix); k != nil && bytes.HasPrefix(k, hashPrefix); k, v = c.Next() {
+			hash = append(hash, Field{fieldNameFromKey(k), append([]byte{}, v...)})
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// hasAnyField reports whether key still has at least one field, without
+// reading the rest of the hash.
+func (b *BoltBackend) hasAnyField(key []byte) bool {
+	hashPrefix := encodeFieldKey(key, nil)
+	found := false
+	err := b.db.View(func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket(boltBucket).Cursor().Seek(hashPrefix)
+		found = k != nil && bytes.HasPrefix(k, hashPrefix)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return found
+}
+
+// GetHash reads the whole hash.
+func (b *BoltBackend) GetHash(key []byte) map[string][]byte {
+	hash := make(map[string][]byte)
+	for _, field := range b.scanAll(key) {
+		hash[string(field.Key)] = field.Value
+	}
+	return hash
+}
+
+// GetHashAsArray reads the whole hash preserving field order.
+func (b *BoltBackend) GetHashAsArray(key []byte) []Field {
+	return b.scanAll(key)
+}
+
+// GetFields reads a set of fields; missing fields come back as nil.
+func (b *BoltBackend) GetFields(key []byte, fields [][]byte) map[string][]byte {
+	hash := make(map[string][]byte)
+	for _, field := range fields {
+		hash[string(field)] = b.get(encodeFieldKey(key, field))
+	}
+	return hash
+}
+
+// GetFieldsAsArray reads a set of fields preserving the requested order.
+func (b *BoltBackend) GetFieldsAsArray(key []byte, fields [][]byte) []Field {
+	hash := []Field{}
+	for _, field := range fields {
+		hash = append(hash, Field{field, b.get(encodeFieldKey(key, field))})
+	}
+	return hash
+}
+
+// DeleteFields removes the given fields, and the key itself if that empties
+// the hash.
+func (b *BoltBackend) DeleteFields(key []byte, fields [][]byte) {
+	keys := [][]byte{}
+	for _, field := range fields {
+		keys = append(keys, encodeFieldKey(key, field))
+	}
+	b.delete(keys)
+
+	if !b.hasAnyField(key) {
+		b.delete([][]byte{encodeMetaKey(key)})
+	}
+}
+
+// GetFieldNames lists all field names in a hash.
+func (b *BoltBackend) GetFieldNames(key []byte) [][]byte {
+	fields := [][]byte{}
+	for _, field := range b.scanAll(key) {
+		fields = append(fields, field.Key)
+	}
+	return fields
+}
+
+// ScanFields pages through a hash's fields, see (*LevelDB).ScanFields.
+func (b *BoltBackend) ScanFields(key []byte, startField []byte, count int) (fields []Field, nextField []byte) {
+	hashPrefix := encodeFieldKey(key, nil)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		k, v := c.Seek(encodeFieldKey(key, startField))
+		for ; k != nil && bytes.HasPrefix(k, hashPrefix) && len(fields) < count; k, v = c.Next() {
+			fields = append(fields, Field{fieldNameFromKey(k), append([]byte{}, v...)})
+		}
+		if k != nil && bytes.HasPrefix(k, hashPrefix) {
+			nextField = fieldNameFromKey(k)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return fields, nextField
+}
+
+// Keys lists every key currently stored, regardless of type, by scanning the
+// metadata keyspace - every key, packed or per-field, has a metadata entry.
+func (b *BoltBackend) Keys() [][]byte {
+	keys := [][]byte{}
+	prefix := []byte{MetaPrefix}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte{}, k[1:]...))
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+// Close releases the backend's underlying resources.
+func (b *BoltBackend) Close() error {
+	return b.db.Close()
+}
+
+var _ Backend = (*BoltBackend)(nil)
@@ -0,0 +1,281 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import (
+	"sort"
+	"sync"
+)
+
+// overlayEntry is the buffered state of one key inside a CacheDB: either a
+// tombstone (deleted), or the key's complete hash as it stands once the
+// transaction commits.
+type overlayEntry struct {
+	deleted bool
+	tipe    byte
+	hash    map[string][]byte
+}
+
+// CacheDB implements Backend by buffering every write in an in-memory
+// overlay over a parent Backend, the way a connection's MULTI/EXEC
+// transaction needs to: reads see the overlay on top of the parent, nothing
+// reaches the parent until Exec, and Discard drops the overlay untouched.
+//
+// It also carries the versions WATCHed keys had when they were watched, so
+// Exec can refuse to commit if any of them changed in the meantime.
+type CacheDB struct {
+	mu sync.Mutex
+
+	parent Backend
+	watch  *Watcher
+
+	watched map[string]uint64
+	overlay map[string]overlayEntry
+}
+
+// NewCacheDB creates a transaction overlay on top of parent. watched is the
+// key -> version map captured by any WATCH issued before MULTI; it may be
+// nil or empty.
+func NewCacheDB(parent Backend, watch *Watcher, watched map[string]uint64) *CacheDB {
+	cd := &CacheDB{
+		parent:  parent,
+		watch:   watch,
+		watched: make(map[string]uint64, len(watched)),
+		overlay: make(map[string]overlayEntry),
+	}
+	for key, version := range watched {
+		cd.watched[key] = version
+	}
+	return cd
+}
+
+// Parent returns the Backend this CacheDB overlays.
+func (cd *CacheDB) Parent() Backend {
+	return cd.parent
+}
+
+// Watch records key's current version, so Exec can later tell whether it
+// changed since this call.
+func (cd *CacheDB) Watch(key []byte) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.watched[string(key)] = cd.watch.Version(key)
+}
+
+// Discard drops every buffered write and watched key, as if MULTI had never
+// been issued.
+func (cd *CacheDB) Discard() {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.overlay = make(map[string]overlayEntry)
+	cd.watched = make(map[string]uint64)
+}
+
+// Exec checks that no watched key's version has moved, and if so, flushes
+// the buffered writes to the parent backend and reports true. Otherwise it
+// leaves the parent untouched and reports false.
+//
+// The check and the flush together must be atomic against every other
+// connection, not just other MULTIs against this same CacheDB - cd.mu alone
+// only excludes callers sharing this CacheDB, never a plain (non-MULTI)
+// write from another connection going straight to parent. So this also
+// takes parent's own lock, the same one every write handler (hset, hdel,
+// ...) takes via ex.DB.Lock(), for the whole check-then-flush.
+func (cd *CacheDB) Exec() bool {
+	cd.parent.Lock()
+	defer cd.parent.Unlock()
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	for key, version := range cd.watched {
+		if cd.watch.Version([]byte(key)) != version {
+			return false
+		}
+	}
+
+	for key, e := range cd.overlay {
+		if e.deleted {
+			cd.parent.DeleteHash([]byte(key))
+			continue
+		}
+		cd.parent.PutHash([]byte(key), e.tipe, e.hash)
+	}
+
+	cd.overlay = make(map[string]overlayEntry)
+	cd.watched = make(map[string]uint64)
+	return true
+}
+
+func (cd *CacheDB) Lock()    { cd.mu.Lock() }
+func (cd *CacheDB) Unlock()  { cd.mu.Unlock() }
+func (cd *CacheDB) RLock()   { cd.mu.Lock() }
+func (cd *CacheDB) RUnlock() { cd.mu.Unlock() }
+
+// materialize returns a mutable copy of key's full hash, consulting the
+// overlay first and falling back to the parent, along with its resp type
+// and whether it exists at all.
+func (cd *CacheDB) materialize(key []byte) (map[string][]byte, byte, bool) {
+	if e, ok := cd.overlay[string(key)]; ok {
+		if e.deleted {
+			return make(map[string][]byte), 0, false
+		}
+		hash := make(map[string][]byte, len(e.hash))
+		for field, value := range e.hash {
+			hash[field] = value
+		}
+		return hash, e.tipe, true
+	}
+
+	exists, tipe := cd.parent.Has(key)
+	if !exists {
+		return make(map[string][]byte), 0, false
+	}
+	return cd.parent.GetHash(key), tipe, true
+}
+
+// sortedFields returns key's field names in lexicographic order (to match
+// the order the underlying backends yield) together with the hash itself.
+func (cd *CacheDB) sortedFields(key []byte) ([]string, map[string][]byte) {
+	hash, _, _ := cd.materialize(key)
+	fields := make([]string, 0, len(hash))
+	for field := range hash {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, hash
+}
+
+// Has reports whether key exists, and if so, the resp type it was stored as.
+func (cd *CacheDB) Has(key []byte) (bool, byte) {
+	if e, ok := cd.overlay[string(key)]; ok {
+		return !e.deleted, e.tipe
+	}
+	return cd.parent.Has(key)
+}
+
+// DeleteHash removes a hash key and all of its fields.
+func (cd *CacheDB) DeleteHash(key []byte) {
+	cd.overlay[string(key)] = overlayEntry{deleted: true}
+}
+
+// PutHash writes hash data, creating or overwriting key's metadata.
+func (cd *CacheDB) PutHash(key []byte, tipe byte, hash map[string][]byte) {
+	merged, _, _ := cd.materialize(key)
+	for field, value := range hash {
+		merged[field] = value
+	}
+	cd.overlay[string(key)] = overlayEntry{tipe: tipe, hash: merged}
+}
+
+// GetHash reads the whole hash.
+func (cd *CacheDB) GetHash(key []byte) map[string][]byte {
+	hash, _, _ := cd.materialize(key)
+	return hash
+}
+
+// GetHashAsArray reads the whole hash preserving field order.
+func (cd *CacheDB) GetHashAsArray(key []byte) []Field {
+	names, hash := cd.sortedFields(key)
+	arr := make([]Field, 0, len(names))
+	for _, field := range names {
+		arr = append(arr, Field{[]byte(field), hash[field]})
+	}
+	return arr
+}
+
+// GetFields reads a set of fields; missing fields come back as nil.
+func (cd *CacheDB) GetFields(key []byte, fields [][]byte) map[string][]byte {
+	hash, _, _ := cd.materialize(key)
+	out := make(map[string][]byte, len(fields))
+	for _, field := range fields {
+		out[string(field)] = hash[string(field)]
+	}
+	return out
+}
+
+// GetFieldsAsArray reads a set of fields preserving the requested order.
+func (cd *CacheDB) GetFieldsAsArray(key []byte, fields [][]byte) []Field {
+	hash, _, _ := cd.materialize(key)
+	out := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		out = append(out, Field{field, hash[string(field)]})
+	}
+	return out
+}
+
+// DeleteFields removes the given fields, and the key itself if that empties
+// the hash.
+func (cd *CacheDB) DeleteFields(key []byte, fields [][]byte) {
+	hash, tipe, exists := cd.materialize(key)
+	if !exists {
+		return
+	}
+	for _, field := range fields {
+		delete(hash, string(field))
+	}
+	if len(hash) == 0 {
+		cd.overlay[string(key)] = overlayEntry{deleted: true}
+		return
+	}
+	cd.overlay[string(key)] = overlayEntry{tipe: tipe, hash: hash}
+}
+
+// GetFieldNames lists all field names in a hash.
+func (cd *CacheDB) GetFieldNames(key []byte) [][]byte {
+	names, _ := cd.sortedFields(key)
+	out := make([][]byte, 0, len(names))
+	for _, field := range names {
+		out = append(out, []byte(field))
+	}
+	return out
+}
+
+// ScanFields pages through a hash's fields, see (*LevelDB).ScanFields.
+func (cd *CacheDB) ScanFields(key []byte, startField []byte, count int) (fields []Field, nextField []byte) {
+	names, hash := cd.sortedFields(key)
+
+	start := sort.SearchStrings(names, string(startField))
+	for i := start; i < len(names) && len(fields) < count; i++ {
+		fields = append(fields, Field{[]byte(names[i]), hash[names[i]]})
+	}
+	if next := start + len(fields); next < len(names) {
+		nextField = []byte(names[next])
+	}
+	return fields, nextField
+}
+
+// Keys lists every key currently stored, regardless of type, reflecting the
+// overlay on top of the parent: keys buffered as deleted are omitted, and
+// keys buffered as written are included even if the parent doesn't have
+// them yet.
+func (cd *CacheDB) Keys() [][]byte {
+	seen := make(map[string]bool, len(cd.overlay))
+	keys := [][]byte{}
+
+	for _, key := range cd.parent.Keys() {
+		seen[string(key)] = true
+		if e, ok := cd.overlay[string(key)]; ok && e.deleted {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	for key, e := range cd.overlay {
+		if seen[key] || e.deleted {
+			continue
+		}
+		keys = append(keys, []byte(key))
+	}
+	return keys
+}
+
+// Close is a no-op: a CacheDB doesn't own the parent's lifecycle.
+func (cd *CacheDB) Close() error {
+	return nil
+}
+
+var _ Backend = (*CacheDB)(nil)
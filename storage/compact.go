@@ -0,0 +1,121 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// HashMaxEntries and HashMaxValue gate the packed (ziplist-style) hash
+// representation in LevelDB: a hash with at most HashMaxEntries fields, none
+// of which (key or value) is longer than HashMaxValue bytes, is stored as a
+// single value instead of one LevelDB key per field. The config file
+// overrides these defaults ("hash-max-entries", "hash-max-value") at
+// start-up.
+var (
+	HashMaxEntries = 128
+	HashMaxValue   = 64
+)
+
+// packedPrefix is the packed representation's own leading byte, distinct
+// from MetaPrefix and ValuePrefix. The metadata keyspace is every byte
+// string starting with MetaPrefix, and the per-field keyspace is every byte
+// string starting with ValuePrefix - since keys are binary-safe, any encoding
+// of the form encodeMetaKey(key)+suffix is really encodeMetaKey(key+suffix)
+// in disguise, and collides with the metadata entry of a second hash
+// literally named key+suffix. A dedicated leading byte that neither keyspace
+// can ever start with is the only way to keep the packed keyspace disjoint
+// from both, whatever bytes a hash's key contains.
+const packedPrefix = 0x02
+
+// encodePackedKey is the single storage key a hash lives under while it uses
+// the packed representation: packedPrefix followed by key verbatim.
+func encodePackedKey(key []byte) []byte {
+	return append([]byte{packedPrefix}, key...)
+}
+
+// fitsPacked reports whether hash is small enough to use the packed
+// representation.
+func fitsPacked(hash map[string][]byte) bool {
+	if len(hash) > HashMaxEntries {
+		return false
+	}
+	for field, value := range hash {
+		if len(field) > HashMaxValue || len(value) > HashMaxValue {
+			return false
+		}
+	}
+	return true
+}
+
+// encodePacked packs a hash into a single buffer:
+// [uvarint count]([uvarint flen][field][uvarint vlen][value])*count,
+// with fields sorted by name so iteration order matches the per-field
+// representation (lexicographic, as goleveldb's iterator yields it).
+func encodePacked(hash map[string][]byte) []byte {
+	fields := make([]string, 0, len(hash))
+	for field := range hash {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(tmp[:], uint64(len(fields)))
+	buf.Write(tmp[:n])
+
+	for _, field := range fields {
+		value := hash[field]
+
+		n = binary.PutUvarint(tmp[:], uint64(len(field)))
+		buf.Write(tmp[:n])
+		buf.WriteString(field)
+
+		n = binary.PutUvarint(tmp[:], uint64(len(value)))
+		buf.Write(tmp[:n])
+		buf.Write(value)
+	}
+	return buf.Bytes()
+}
+
+// decodePacked is the inverse of encodePacked, returning fields already
+// sorted by field name.
+func decodePacked(data []byte) []Field {
+	buf := bytes.NewReader(data)
+
+	count, err := binary.ReadUvarint(buf)
+	if err != nil {
+		panic(err)
+	}
+
+	fields := make([]Field, 0, count)
+	for i := uint64(0); i < count; i++ {
+		flen, err := binary.ReadUvarint(buf)
+		if err != nil {
+			panic(err)
+		}
+		field := make([]byte, flen)
+		if _, err := io.ReadFull(buf, field); err != nil {
+			panic(err)
+		}
+
+		vlen, err := binary.ReadUvarint(buf)
+		if err != nil {
+			panic(err)
+		}
+		value := make([]byte, vlen)
+		if _, err := io.ReadFull(buf, value); err != nil {
+			panic(err)
+		}
+
+		fields = append(fields, Field{field, value})
+	}
+	return fields
+}
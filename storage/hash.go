@@ -6,6 +6,8 @@
 package storage
 
 import (
+	"bytes"
+	"sort"
 	"strings"
 
 	"github.com/syndtr/goleveldb/leveldb"
@@ -26,9 +28,41 @@ func encodeFieldKey(key []byte, field []byte) []byte {
 	return fieldKey
 }
 
+// packedFields returns key's fields and true if key currently uses the
+// packed (single-value) representation, or nil and false if it uses the
+// per-field layout (or doesn't exist).
+func (ldb *LevelDB) packedFields(key []byte) ([]Field, bool) {
+	data := ldb.get(encodePackedKey(key))
+	if data == nil {
+		return nil, false
+	}
+	return decodePacked(data), true
+}
+
+// fieldCount counts key's per-field entries, stopping as soon as it passes
+// limit: count is the number of keys seen, capped at limit+1, and exceeds
+// reports whether the real count is actually more than limit. Only the
+// keys are read, not their values, and scanning stops the moment a
+// migration down to the packed representation is known to be impossible,
+// so checking a huge per-field hash's size never costs more than
+// HashMaxEntries+1 key reads.
+func (ldb *LevelDB) fieldCount(key []byte, limit int) (count int, exceeds bool) {
+	hashPrefix := encodeFieldKey(key, nil)
+	iter := ldb.db.NewIterator(util.BytesPrefix(hashPrefix), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		count++
+		if count > limit {
+			return count, true
+		}
+	}
+	return count, false
+}
+
 // DeleteHash deletes all hash data
 func (ldb *LevelDB) DeleteHash(key []byte) {
-	keys := [][]byte{encodeMetaKey(key)}
+	keys := [][]byte{encodeMetaKey(key), encodePackedKey(key)}
 
 	// enum fields, and delete all
 	hashPrefix := encodeFieldKey(key, nil)
@@ -40,8 +74,76 @@ func (ldb *LevelDB) DeleteHash(key []byte) {
 	ldb.delete(keys)
 }
 
+// putPacked writes hash using the packed representation, as the new
+// complete set of fields for key.
+func (ldb *LevelDB) putPacked(key []byte, tipe byte, hash map[string][]byte) {
+	batch := new(leveldb.Batch)
+	batch.Put(encodeMetaKey(key), encodeMetadata(tipe))
+	batch.Put(encodePackedKey(key), encodePacked(hash))
+	if err := ldb.db.Write(batch, nil); err != nil {
+		panic(err)
+	}
+}
+
+// putMerged writes hash - already merged with whatever fields existed
+// before - choosing the packed or per-field representation based on size,
+// and migrating away from the packed key if hash no longer fits it.
+func (ldb *LevelDB) putMerged(key []byte, tipe byte, hash map[string][]byte) {
+	if fitsPacked(hash) {
+		ldb.putPacked(key, tipe, hash)
+		return
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(encodeMetaKey(key), encodeMetadata(tipe))
+	batch.Delete(encodePackedKey(key))
+	for field, value := range hash {
+		batch.Put(encodeFieldKey(key, []byte(field)), value)
+	}
+	if err := ldb.db.Write(batch, nil); err != nil {
+		panic(err)
+	}
+}
+
+// packDown migrates key from the per-field layout to the packed
+// representation: hash is its complete, already-collected set of fields, and
+// oldKeys are the per-field storage keys that held them, which are removed
+// in the same batch as the packed value is written.
+func (ldb *LevelDB) packDown(key []byte, tipe byte, hash map[string][]byte, oldKeys [][]byte) {
+	batch := new(leveldb.Batch)
+	batch.Put(encodeMetaKey(key), encodeMetadata(tipe))
+	batch.Put(encodePackedKey(key), encodePacked(hash))
+	for _, k := range oldKeys {
+		batch.Delete(k)
+	}
+	if err := ldb.db.Write(batch, nil); err != nil {
+		panic(err)
+	}
+}
+
 // PutHash write hash data
 func (ldb *LevelDB) PutHash(key []byte, tipe byte, hash map[string][]byte) {
+	if packed, ok := ldb.packedFields(key); ok {
+		merged := make(map[string][]byte, len(packed)+len(hash))
+		for _, f := range packed {
+			merged[string(f.Key)] = f.Value
+		}
+		for field, value := range hash {
+			merged[field] = value
+		}
+		ldb.putMerged(key, tipe, merged)
+		return
+	}
+
+	exists, _ := ldb.Has(key)
+	if !exists && fitsPacked(hash) {
+		ldb.putPacked(key, tipe, hash)
+		return
+	}
+
+	// Already using, or starting directly with, the per-field layout:
+	// write just the given fields, same as before the packed
+	// representation existed.
 	batch := new(leveldb.Batch)
 	batch.Put(encodeMetaKey(key), encodeMetadata(tipe))
 	for k, v := range hash {
@@ -50,12 +152,44 @@ func (ldb *LevelDB) PutHash(key []byte, tipe byte, hash map[string][]byte) {
 	if err := ldb.db.Write(batch, nil); err != nil {
 		panic(err)
 	}
+
+	if !exists {
+		return
+	}
+
+	// The hash already existed in the per-field layout: check whether it -
+	// now including the fields just written - has shrunk small enough to
+	// migrate down to the packed representation, the mirror image of the
+	// down-migration DeleteFields does on shrink. fieldCount bails out
+	// before reading a single value once the count passes HashMaxEntries,
+	// so writing a field into a hash with millions of them stays cheap.
+	if _, exceeds := ldb.fieldCount(key, HashMaxEntries); exceeds {
+		return
+	}
+
+	existing := ldb.GetHashAsArray(key)
+	merged := make(map[string][]byte, len(existing))
+	oldKeys := make([][]byte, len(existing))
+	for i, f := range existing {
+		merged[string(f.Key)] = f.Value
+		oldKeys[i] = encodeFieldKey(key, f.Key)
+	}
+	if fitsPacked(merged) {
+		ldb.packDown(key, tipe, merged, oldKeys)
+	}
 }
 
 // GetHash gets hash data
 func (ldb *LevelDB) GetHash(key []byte) map[string][]byte {
 	hash := make(map[string][]byte)
 
+	if packed, ok := ldb.packedFields(key); ok {
+		for _, f := range packed {
+			hash[string(f.Key)] = f.Value
+		}
+		return hash
+	}
+
 	hashPrefix := encodeFieldKey(key, nil)
 	iter := ldb.db.NewIterator(util.BytesPrefix(hashPrefix), nil)
 	for iter.Next() {
@@ -72,6 +206,10 @@ func (ldb *LevelDB) GetHash(key []byte) map[string][]byte {
 
 // GetHashAsArray gets hash data as array to ensure the insertion sort
 func (ldb *LevelDB) GetHashAsArray(key []byte) []Field {
+	if packed, ok := ldb.packedFields(key); ok {
+		return packed
+	}
+
 	hash := []Field{}
 
 	hashPrefix := encodeFieldKey(key, nil)
@@ -90,6 +228,29 @@ func (ldb *LevelDB) GetHashAsArray(key []byte) []Field {
 
 // DeleteHashFields deletes hash fields
 func (ldb *LevelDB) DeleteFields(key []byte, fields [][]byte) {
+	if packed, ok := ldb.packedFields(key); ok {
+		remove := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			remove[string(field)] = true
+		}
+
+		remaining := make(map[string][]byte)
+		for _, f := range packed {
+			if !remove[string(f.Key)] {
+				remaining[string(f.Key)] = f.Value
+			}
+		}
+
+		if len(remaining) == 0 {
+			ldb.delete([][]byte{encodeMetaKey(key), encodePackedKey(key)})
+			return
+		}
+
+		_, tipe := ldb.Has(key)
+		ldb.putPacked(key, tipe, remaining)
+		return
+	}
+
 	// Delete fields
 	keys := [][]byte{}
 	for _, field := range fields {
@@ -97,18 +258,49 @@ func (ldb *LevelDB) DeleteFields(key []byte, fields [][]byte) {
 	}
 	ldb.delete(keys)
 
-	// After delete, remove the hash meta entry if no fields in this hash
-	hashPrefix := encodeFieldKey(key, nil)
-	iter := ldb.db.NewIterator(util.BytesPrefix(hashPrefix), nil)
-	if !iter.Next() {
-		ldb.delete([][]byte{encodeMetaKey(key)}) // No field, delete the hash
+	// After delete, remove the hash meta entry if no fields remain, or
+	// migrate down to the packed representation if what's left now fits -
+	// the mirror image of PutHash migrating up on growth. fieldCount
+	// checks the size without reading any values, so deleting a field or
+	// two off a huge per-field hash doesn't pay to read every remaining
+	// value just to learn it's still nowhere near packable.
+	count, exceeds := ldb.fieldCount(key, HashMaxEntries)
+	if count == 0 {
+		ldb.delete([][]byte{encodeMetaKey(key)})
+		return
+	}
+	if exceeds {
+		return
+	}
+
+	remaining := ldb.GetHashAsArray(key)
+	hash := make(map[string][]byte, len(remaining))
+	oldKeys := make([][]byte, len(remaining))
+	for i, f := range remaining {
+		hash[string(f.Key)] = f.Value
+		oldKeys[i] = encodeFieldKey(key, f.Key)
+	}
+	if fitsPacked(hash) {
+		_, tipe := ldb.Has(key)
+		ldb.packDown(key, tipe, hash, oldKeys)
 	}
-	iter.Release()
 }
 
 // GetFields get hash fields
 func (ldb *LevelDB) GetFields(key []byte, fields [][]byte) map[string][]byte {
 	hash := make(map[string][]byte)
+
+	if packed, ok := ldb.packedFields(key); ok {
+		lookup := make(map[string][]byte, len(packed))
+		for _, f := range packed {
+			lookup[string(f.Key)] = f.Value
+		}
+		for _, field := range fields {
+			hash[string(field)] = lookup[string(field)]
+		}
+		return hash
+	}
+
 	for _, field := range fields {
 		fieldValue := ldb.get(encodeFieldKey(key, field))
 		hash[string(field)] = fieldValue
@@ -118,6 +310,14 @@ func (ldb *LevelDB) GetFields(key []byte, fields [][]byte) map[string][]byte {
 
 // GetFieldNames gets hash field names
 func (ldb *LevelDB) GetFieldNames(key []byte) [][]byte {
+	if packed, ok := ldb.packedFields(key); ok {
+		fields := make([][]byte, 0, len(packed))
+		for _, f := range packed {
+			fields = append(fields, f.Key)
+		}
+		return fields
+	}
+
 	fields := [][]byte{}
 
 	hashPrefix := encodeFieldKey(key, nil)
@@ -136,9 +336,69 @@ func (ldb *LevelDB) GetFieldNames(key []byte) [][]byte {
 // GetHashFieldNamesAsArray gets hash fields as array
 func (ldb *LevelDB) GetFieldsAsArray(key []byte, fields [][]byte) []Field {
 	hash := []Field{}
+
+	if packed, ok := ldb.packedFields(key); ok {
+		lookup := make(map[string][]byte, len(packed))
+		for _, f := range packed {
+			lookup[string(f.Key)] = f.Value
+		}
+		for _, field := range fields {
+			hash = append(hash, Field{field, lookup[string(field)]})
+		}
+		return hash
+	}
+
 	for _, field := range fields {
 		value := ldb.get(encodeFieldKey(key, field))
 		hash = append(hash, Field{field, value})
 	}
 	return hash
 }
+
+// ScanFields returns up to count field/value pairs starting at startField (nil
+// to start from the beginning), along with the next unread field name to resume
+// from. nextField is nil once the hash has been fully iterated.
+func (ldb *LevelDB) ScanFields(key []byte, startField []byte, count int) (fields []Field, nextField []byte) {
+	if packed, ok := ldb.packedFields(key); ok {
+		start := sort.Search(len(packed), func(i int) bool {
+			return bytes.Compare(packed[i].Key, startField) >= 0
+		})
+		for i := start; i < len(packed) && len(fields) < count; i++ {
+			fields = append(fields, packed[i])
+		}
+		if next := start + len(fields); next < len(packed) {
+			nextField = packed[next].Key
+		}
+		return fields, nextField
+	}
+
+	hashPrefix := encodeFieldKey(key, nil)
+	iter := ldb.db.NewIterator(util.BytesPrefix(hashPrefix), nil)
+	defer iter.Release()
+
+	ok := iter.Seek(encodeFieldKey(key, startField))
+	for ; ok && len(fields) < count; ok = iter.Next() {
+		sepIndex := strings.IndexByte(string(iter.Key()), '|')
+		field := append([]byte{}, iter.Key()[sepIndex+1:]...)
+		value := append([]byte{}, iter.Value()...)
+		fields = append(fields, Field{field, value})
+	}
+
+	if ok {
+		sepIndex := strings.IndexByte(string(iter.Key()), '|')
+		nextField = append([]byte{}, iter.Key()[sepIndex+1:]...)
+	}
+	return fields, nextField
+}
+
+// Keys lists every key currently stored, regardless of type, by scanning the
+// metadata keyspace - every key, packed or per-field, has a metadata entry.
+func (ldb *LevelDB) Keys() [][]byte {
+	keys := [][]byte{}
+	iter := ldb.db.NewIterator(util.BytesPrefix([]byte{MetaPrefix}), nil)
+	for iter.Next() {
+		keys = append(keys, append([]byte{}, iter.Key()[1:]...))
+	}
+	iter.Release()
+	return keys
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryBackend is a pure in-memory Backend, mainly intended for tests: it
+// keeps every key in a Go map instead of touching disk, so a process restart
+// loses everything.
+type MemoryBackend struct {
+	mu    sync.RWMutex
+	types map[string]byte
+	hash  map[string]map[string][]byte
+}
+
+// NewMemoryBackend creates an empty MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		types: make(map[string]byte),
+		hash:  make(map[string]map[string][]byte),
+	}
+}
+
+func (m *MemoryBackend) Lock()    { m.mu.Lock() }
+func (m *MemoryBackend) Unlock()  { m.mu.Unlock() }
+func (m *MemoryBackend) RLock()   { m.mu.RLock() }
+func (m *MemoryBackend) RUnlock() { m.mu.RUnlock() }
+
+// Has reports whether key exists, and if so, the resp type it was stored as.
+func (m *MemoryBackend) Has(key []byte) (bool, byte) {
+	tipe, ok := m.types[string(key)]
+	return ok, tipe
+}
+
+// DeleteHash removes a hash key and all of its fields.
+func (m *MemoryBackend) DeleteHash(key []byte) {
+	delete(m.types, string(key))
+	delete(m.hash, string(key))
+}
+
+// PutHash writes hash data, creating or overwriting key's metadata.
+func (m *MemoryBackend) PutHash(key []byte, tipe byte, hash map[string][]byte) {
+	m.types[string(key)] = tipe
+
+	h, ok := m.hash[string(key)]
+	if !ok {
+		h = make(map[string][]byte)
+		m.hash[string(key)] = h
+	}
+	for field, value := range hash {
+		h[field] = value
+	}
+}
+
+// GetHash reads the whole hash.
+func (m *MemoryBackend) GetHash(key []byte) map[string][]byte {
+	hash := make(map[string][]byte)
+	for field, value := range m.hash[string(key)] {
+		hash[field] = value
+	}
+	return hash
+}
+
+// sortedFields returns the field names of key's hash in byte-lexicographic
+// order, matching the iteration order the LevelDB backend yields.
+func (m *MemoryBackend) sortedFields(key []byte) []string {
+	h := m.hash[string(key)]
+	fields := make([]string, 0, len(h))
+	for field := range h {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// GetHashAsArray reads the whole hash preserving field order.
+func (m *MemoryBackend) GetHashAsArray(key []byte) []Field {
+	hash := []Field{}
+	h := m.hash[string(key)]
+	for _, field := range m.sortedFields(key) {
+		hash = append(hash, Field{[]byte(field), h[field]})
+	}
+	return hash
+}
+
+// GetFields reads a set of fields; missing fields come back as nil.
+func (m *MemoryBackend) GetFields(key []byte, fields [][]byte) map[string][]byte {
+	hash := make(map[string][]byte)
+	h := m.hash[string(key)]
+	for _, field := range fields {
+		hash[string(field)] = h[string(field)]
+	}
+	return hash
+}
+
+// GetFieldsAsArray reads a set of fields preserving the requested order.
+func (m *MemoryBackend) GetFieldsAsArray(key []byte, fields [][]byte) []Field {
+	hash := []Field{}
+	h := m.hash[string(key)]
+	for _, field := range fields {
+		hash = append(hash, Field{field, h[string(field)]})
+	}
+	return hash
+}
+
+// DeleteFields removes the given fields, and the key itself if that empties
+// the hash.
+func (m *MemoryBackend) DeleteFields(key []byte, fields [][]byte) {
+	h, ok := m.hash[string(key)]
+	if !ok {
+		return
+	}
+	for _, field := range fields {
+		delete(h, string(field))
+	}
+	if len(h) == 0 {
+		m.DeleteHash(key)
+	}
+}
+
+// GetFieldNames lists all field names in a hash.
+func (m *MemoryBackend) GetFieldNames(key []byte) [][]byte {
+	fields := [][]byte{}
+	for _, field := range m.sortedFields(key) {
+		fields = append(fields, []byte(field))
+	}
+	return fields
+}
+
+// ScanFields pages through a hash's fields, see (*LevelDB).ScanFields.
+func (m *MemoryBackend) ScanFields(key []byte, startField []byte, count int) (fields []Field, nextField []byte) {
+	h := m.hash[string(key)]
+	names := m.sortedFields(key)
+
+	start := sort.SearchStrings(names, string(startField))
+	for i := start; i < len(names) && len(fields) < count; i++ {
+		fields = append(fields, Field{[]byte(names[i]), h[names[i]]})
+	}
+
+	if next := start + len(fields); next < len(names) {
+		nextField = []byte(names[next])
+	}
+	return fields, nextField
+}
+
+// Keys lists every key currently stored, regardless of type.
+func (m *MemoryBackend) Keys() [][]byte {
+	keys := make([]string, 0, len(m.types))
+	for key := range m.types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, []byte(key))
+	}
+	return out
+}
+
+// Close is a no-op, MemoryBackend holds no external resources.
+func (m *MemoryBackend) Close() error {
+	return nil
+}
+
+var _ Backend = (*MemoryBackend)(nil)
@@ -0,0 +1,307 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package remote
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/rod6/rodis/storage"
+	"github.com/rod6/rodis/storage/remote/pb"
+)
+
+// RemoteBackend is a storage.Backend backed by a rodis-storaged instance
+// reached over gRPC.
+type RemoteBackend struct {
+	conn   *grpc.ClientConn
+	client pb.BackendClient
+
+	wMu     sync.Mutex
+	wStream pb.Backend_LockSessionClient
+
+	rMu     sync.Mutex
+	rIdle   []pb.Backend_LockSessionClient // open streams, currently unlocked
+	rHeld   []pb.Backend_LockSessionClient // open streams, currently locked
+}
+
+// Dial connects to the rodis-storaged instance at addr. creds is nil for a
+// plaintext connection, or mTLS transport credentials otherwise.
+func Dial(addr string, creds credentials.TransportCredentials) (*RemoteBackend, error) {
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(pb.WireCodec{})),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteBackend{conn: conn, client: pb.NewBackendClient(conn)}, nil
+}
+
+// newLockStream opens a fresh LockSession stream, ready for acquireOn.
+func (r *RemoteBackend) newLockStream() pb.Backend_LockSessionClient {
+	stream, err := r.client.LockSession(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	return stream
+}
+
+// acquireOn sends a request to acquire the lock in mode on stream and blocks
+// until the server has granted it.
+func acquireOn(stream pb.Backend_LockSessionClient, mode int32) {
+	if err := stream.Send(&pb.LockSessionRequest{Mode: mode}); err != nil {
+		panic(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		panic(err)
+	}
+}
+
+// releaseOn releases a lock acquired by acquireOn, leaving stream open so it
+// can be reused for a later acquireOn instead of paying to open a new
+// stream for every single Lock/Unlock or RLock/RUnlock cycle.
+func releaseOn(stream pb.Backend_LockSessionClient) {
+	if err := stream.Send(&pb.LockSessionRequest{Release: true}); err != nil {
+		panic(err)
+	}
+}
+
+// Lock/Unlock/RLock/RUnlock serialize access across every connected client
+// by holding the server's lock for as long as a LockSession stream has an
+// outstanding, unreleased acquire request on it - see Server.LockSession. A
+// write lock reuses a single stream, since only one Lock()/Unlock() pair
+// can be outstanding at a time; read locks reuse a pool of streams, since
+// several RLock() calls can be outstanding concurrently and RUnlock
+// releases whichever one of them finished first, exactly as sync.RWMutex's
+// RLock/RUnlock don't need to be paired by identity either. Reusing streams
+// across calls, rather than opening and closing one per call, amortizes
+// away the stream-setup round trip that would otherwise be paid on every
+// single command.
+//
+// grpc-go forbids concurrent SendMsg/RecvMsg on one stream from different
+// goroutines, and the server's LockSession loop treats the very next Recv
+// on a stream as that stream's release - it has no way to tell one caller's
+// acquire from another's. So wMu must stay held for the whole Lock/Unlock
+// critical section, not just while picking wStream, or two goroutines
+// racing to Lock() would interleave acquireOn/releaseOn calls on the same
+// stream.
+func (r *RemoteBackend) Lock() {
+	r.wMu.Lock()
+	if r.wStream == nil {
+		r.wStream = r.newLockStream()
+	}
+
+	acquireOn(r.wStream, pb.LockModeWrite)
+}
+
+func (r *RemoteBackend) Unlock() {
+	releaseOn(r.wStream)
+
+	r.wMu.Unlock()
+}
+
+func (r *RemoteBackend) RLock() {
+	r.rMu.Lock()
+	var stream pb.Backend_LockSessionClient
+	if n := len(r.rIdle); n > 0 {
+		stream = r.rIdle[n-1]
+		r.rIdle = r.rIdle[:n-1]
+	}
+	r.rMu.Unlock()
+
+	if stream == nil {
+		stream = r.newLockStream()
+	}
+	acquireOn(stream, pb.LockModeRead)
+
+	r.rMu.Lock()
+	r.rHeld = append(r.rHeld, stream)
+	r.rMu.Unlock()
+}
+
+func (r *RemoteBackend) RUnlock() {
+	r.rMu.Lock()
+	n := len(r.rHeld)
+	stream := r.rHeld[n-1]
+	r.rHeld = r.rHeld[:n-1]
+	r.rMu.Unlock()
+
+	releaseOn(stream)
+
+	r.rMu.Lock()
+	r.rIdle = append(r.rIdle, stream)
+	r.rMu.Unlock()
+}
+
+func (r *RemoteBackend) Has(key []byte) (bool, byte) {
+	resp, err := r.client.Has(context.Background(), &pb.HasRequest{Key: key})
+	if err != nil {
+		panic(err)
+	}
+	return resp.Exists, byte(resp.Type)
+}
+
+func (r *RemoteBackend) DeleteHash(key []byte) {
+	if _, err := r.client.DeleteHash(context.Background(), &pb.DeleteHashRequest{Key: key}); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteBackend) PutHash(key []byte, tipe byte, hash map[string][]byte) {
+	req := &pb.PutHashRequest{Key: key, Type: uint32(tipe)}
+	for field, value := range hash {
+		req.Hash = append(req.Hash, &pb.HashEntry{Field: []byte(field), Value: value})
+	}
+	if _, err := r.client.PutHash(context.Background(), req); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteBackend) GetHash(key []byte) map[string][]byte {
+	hash := make(map[string][]byte)
+	for _, f := range r.streamHash(key) {
+		hash[string(f.Key)] = f.Value
+	}
+	return hash
+}
+
+func (r *RemoteBackend) GetHashAsArray(key []byte) []storage.Field {
+	return r.streamHash(key)
+}
+
+func (r *RemoteBackend) streamHash(key []byte) []storage.Field {
+	stream, err := r.client.GetHashAsArray(context.Background(), &pb.GetHashRequest{Key: key})
+	if err != nil {
+		panic(err)
+	}
+
+	fields := []storage.Field{}
+	for {
+		f, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		fields = append(fields, storage.Field{Key: f.Key, Value: f.Value})
+	}
+	return fields
+}
+
+func (r *RemoteBackend) GetFields(key []byte, fields [][]byte) map[string][]byte {
+	resp, err := r.client.GetFields(context.Background(), &pb.GetFieldsRequest{Key: key, Fields: fields})
+	if err != nil {
+		panic(err)
+	}
+
+	hash := make(map[string][]byte, len(resp.Fields))
+	for _, f := range resp.Fields {
+		hash[string(f.Key)] = f.Value
+	}
+	return hash
+}
+
+func (r *RemoteBackend) GetFieldsAsArray(key []byte, fields [][]byte) []storage.Field {
+	stream, err := r.client.GetFieldsAsArray(context.Background(), &pb.GetFieldsRequest{Key: key, Fields: fields})
+	if err != nil {
+		panic(err)
+	}
+
+	out := []storage.Field{}
+	for {
+		f, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, storage.Field{Key: f.Key, Value: f.Value})
+	}
+	return out
+}
+
+func (r *RemoteBackend) DeleteFields(key []byte, fields [][]byte) {
+	if _, err := r.client.DeleteFields(context.Background(), &pb.DeleteFieldsRequest{Key: key, Fields: fields}); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteBackend) GetFieldNames(key []byte) [][]byte {
+	stream, err := r.client.GetFieldNames(context.Background(), &pb.GetFieldNamesRequest{Key: key})
+	if err != nil {
+		panic(err)
+	}
+
+	names := [][]byte{}
+	for {
+		f, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func (r *RemoteBackend) ScanFields(key []byte, startField []byte, count int) ([]storage.Field, []byte) {
+	resp, err := r.client.ScanFields(context.Background(), &pb.ScanFieldsRequest{
+		Key:        key,
+		StartField: startField,
+		Count:      int32(count),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fields := make([]storage.Field, 0, len(resp.Fields))
+	for _, f := range resp.Fields {
+		fields = append(fields, storage.Field{Key: f.Key, Value: f.Value})
+	}
+	return fields, resp.NextField
+}
+
+func (r *RemoteBackend) Keys() [][]byte {
+	stream, err := r.client.Keys(context.Background(), &pb.KeysRequest{})
+	if err != nil {
+		panic(err)
+	}
+
+	keys := [][]byte{}
+	for {
+		k, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, k.Key)
+	}
+	return keys
+}
+
+// Close closes the underlying gRPC connection.
+func (r *RemoteBackend) Close() error {
+	return r.conn.Close()
+}
+
+var _ storage.Backend = (*RemoteBackend)(nil)
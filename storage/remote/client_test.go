@@ -0,0 +1,98 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package remote
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rod6/rodis/storage"
+)
+
+// dialTestServer starts a Server backed by a fresh MemoryBackend on an
+// ephemeral local port and returns a RemoteBackend dialed to it, along with
+// a cleanup func.
+func dialTestServer(t *testing.T) (*RemoteBackend, func()) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+
+	srv := NewServer(storage.NewMemoryBackend())
+	go Serve(lis, srv, nil)
+
+	client, err := Dial(lis.Addr().String(), nil)
+	if err != nil {
+		lis.Close()
+		t.Fatalf("Dial: %v", err)
+	}
+
+	return client, func() {
+		client.conn.Close()
+		lis.Close()
+	}
+}
+
+// TestLockConcurrent stress-tests Lock/Unlock from many goroutines sharing
+// one RemoteBackend - the normal case, since every client connection on a
+// frontend shares one RemoteBackend just as they'd share one *LevelDB. It
+// guards against the race fixed in client.go's Lock/Unlock: acquireOn and
+// releaseOn must never run concurrently on the same wStream, or the
+// server's LockSession loop (which treats the very next Recv as the
+// release) hands one goroutine's acquire ack to another, or hangs forever.
+func TestLockConcurrent(t *testing.T) {
+	client, cleanup := dialTestServer(t)
+	defer cleanup()
+
+	const goroutines = 20
+	const rounds = 20
+
+	var mu sync.Mutex
+	locked := false
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				client.Lock()
+
+				mu.Lock()
+				if locked {
+					mu.Unlock()
+					t.Errorf("Lock: held concurrently by two goroutines")
+					client.Unlock()
+					return
+				}
+				locked = true
+				mu.Unlock()
+
+				mu.Lock()
+				locked = false
+				mu.Unlock()
+
+				client.Unlock()
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Lock/Unlock deadlocked under concurrent callers")
+	}
+}
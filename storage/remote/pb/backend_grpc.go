@@ -0,0 +1,592 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package pb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// This file is the hand-written equivalent of what protoc-gen-go-grpc would
+// generate from the Backend service in remote.proto - same method and type
+// names, same client/server interfaces, same ServiceDesc shape - so that
+// server.go and client.go read exactly as they would against generated
+// code. It exists by hand because there's no protoc available to run
+// //go:generate against remote.proto in this environment.
+
+// BackendClient is the client API for the Backend service.
+type BackendClient interface {
+	LockSession(ctx context.Context, opts ...grpc.CallOption) (Backend_LockSessionClient, error)
+	Has(ctx context.Context, in *HasRequest, opts ...grpc.CallOption) (*HasResponse, error)
+	DeleteHash(ctx context.Context, in *DeleteHashRequest, opts ...grpc.CallOption) (*DeleteHashResponse, error)
+	PutHash(ctx context.Context, in *PutHashRequest, opts ...grpc.CallOption) (*PutHashResponse, error)
+	GetHash(ctx context.Context, in *GetHashRequest, opts ...grpc.CallOption) (Backend_GetHashClient, error)
+	GetHashAsArray(ctx context.Context, in *GetHashRequest, opts ...grpc.CallOption) (Backend_GetHashAsArrayClient, error)
+	GetFields(ctx context.Context, in *GetFieldsRequest, opts ...grpc.CallOption) (*GetFieldsResponse, error)
+	GetFieldsAsArray(ctx context.Context, in *GetFieldsRequest, opts ...grpc.CallOption) (Backend_GetFieldsAsArrayClient, error)
+	DeleteFields(ctx context.Context, in *DeleteFieldsRequest, opts ...grpc.CallOption) (*DeleteFieldsResponse, error)
+	GetFieldNames(ctx context.Context, in *GetFieldNamesRequest, opts ...grpc.CallOption) (Backend_GetFieldNamesClient, error)
+	ScanFields(ctx context.Context, in *ScanFieldsRequest, opts ...grpc.CallOption) (*ScanFieldsResponse, error)
+	Keys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (Backend_KeysClient, error)
+}
+
+type backendClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewBackendClient wraps cc as a BackendClient.
+func NewBackendClient(cc grpc.ClientConnInterface) BackendClient {
+	return &backendClient{cc}
+}
+
+func (c *backendClient) LockSession(ctx context.Context, opts ...grpc.CallOption) (Backend_LockSessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &backendServiceDesc.Streams[0], "/remote.Backend/LockSession", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &backendLockSessionClient{stream}, nil
+}
+
+type Backend_LockSessionClient interface {
+	Send(*LockSessionRequest) error
+	Recv() (*LockSessionResponse, error)
+	grpc.ClientStream
+}
+
+type backendLockSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendLockSessionClient) Send(m *LockSessionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *backendLockSessionClient) Recv() (*LockSessionResponse, error) {
+	m := new(LockSessionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) Has(ctx context.Context, in *HasRequest, opts ...grpc.CallOption) (*HasResponse, error) {
+	out := new(HasResponse)
+	if err := c.cc.Invoke(ctx, "/remote.Backend/Has", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) DeleteHash(ctx context.Context, in *DeleteHashRequest, opts ...grpc.CallOption) (*DeleteHashResponse, error) {
+	out := new(DeleteHashResponse)
+	if err := c.cc.Invoke(ctx, "/remote.Backend/DeleteHash", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) PutHash(ctx context.Context, in *PutHashRequest, opts ...grpc.CallOption) (*PutHashResponse, error) {
+	out := new(PutHashResponse)
+	if err := c.cc.Invoke(ctx, "/remote.Backend/PutHash", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type Backend_GetHashClient interface {
+	Recv() (*Field, error)
+	grpc.ClientStream
+}
+
+type backendGetHashClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendGetHashClient) Recv() (*Field, error) {
+	m := new(Field)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) GetHash(ctx context.Context, in *GetHashRequest, opts ...grpc.CallOption) (Backend_GetHashClient, error) {
+	stream, err := c.cc.NewStream(ctx, &backendServiceDesc.Streams[1], "/remote.Backend/GetHash", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendGetHashClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Backend_GetHashAsArrayClient interface {
+	Recv() (*Field, error)
+	grpc.ClientStream
+}
+
+type backendGetHashAsArrayClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendGetHashAsArrayClient) Recv() (*Field, error) {
+	m := new(Field)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) GetHashAsArray(ctx context.Context, in *GetHashRequest, opts ...grpc.CallOption) (Backend_GetHashAsArrayClient, error) {
+	stream, err := c.cc.NewStream(ctx, &backendServiceDesc.Streams[2], "/remote.Backend/GetHashAsArray", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendGetHashAsArrayClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (c *backendClient) GetFields(ctx context.Context, in *GetFieldsRequest, opts ...grpc.CallOption) (*GetFieldsResponse, error) {
+	out := new(GetFieldsResponse)
+	if err := c.cc.Invoke(ctx, "/remote.Backend/GetFields", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type Backend_GetFieldsAsArrayClient interface {
+	Recv() (*Field, error)
+	grpc.ClientStream
+}
+
+type backendGetFieldsAsArrayClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendGetFieldsAsArrayClient) Recv() (*Field, error) {
+	m := new(Field)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) GetFieldsAsArray(ctx context.Context, in *GetFieldsRequest, opts ...grpc.CallOption) (Backend_GetFieldsAsArrayClient, error) {
+	stream, err := c.cc.NewStream(ctx, &backendServiceDesc.Streams[3], "/remote.Backend/GetFieldsAsArray", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendGetFieldsAsArrayClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (c *backendClient) DeleteFields(ctx context.Context, in *DeleteFieldsRequest, opts ...grpc.CallOption) (*DeleteFieldsResponse, error) {
+	out := new(DeleteFieldsResponse)
+	if err := c.cc.Invoke(ctx, "/remote.Backend/DeleteFields", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type Backend_GetFieldNamesClient interface {
+	Recv() (*FieldName, error)
+	grpc.ClientStream
+}
+
+type backendGetFieldNamesClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendGetFieldNamesClient) Recv() (*FieldName, error) {
+	m := new(FieldName)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) GetFieldNames(ctx context.Context, in *GetFieldNamesRequest, opts ...grpc.CallOption) (Backend_GetFieldNamesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &backendServiceDesc.Streams[4], "/remote.Backend/GetFieldNames", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendGetFieldNamesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (c *backendClient) ScanFields(ctx context.Context, in *ScanFieldsRequest, opts ...grpc.CallOption) (*ScanFieldsResponse, error) {
+	out := new(ScanFieldsResponse)
+	if err := c.cc.Invoke(ctx, "/remote.Backend/ScanFields", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type Backend_KeysClient interface {
+	Recv() (*Key, error)
+	grpc.ClientStream
+}
+
+type backendKeysClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendKeysClient) Recv() (*Key, error) {
+	m := new(Key)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) Keys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (Backend_KeysClient, error) {
+	stream, err := c.cc.NewStream(ctx, &backendServiceDesc.Streams[5], "/remote.Backend/Keys", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendKeysClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// BackendServer is the server API for the Backend service.
+type BackendServer interface {
+	LockSession(Backend_LockSessionServer) error
+	Has(context.Context, *HasRequest) (*HasResponse, error)
+	DeleteHash(context.Context, *DeleteHashRequest) (*DeleteHashResponse, error)
+	PutHash(context.Context, *PutHashRequest) (*PutHashResponse, error)
+	GetHash(*GetHashRequest, Backend_GetHashServer) error
+	GetHashAsArray(*GetHashRequest, Backend_GetHashAsArrayServer) error
+	GetFields(context.Context, *GetFieldsRequest) (*GetFieldsResponse, error)
+	GetFieldsAsArray(*GetFieldsRequest, Backend_GetFieldsAsArrayServer) error
+	DeleteFields(context.Context, *DeleteFieldsRequest) (*DeleteFieldsResponse, error)
+	GetFieldNames(*GetFieldNamesRequest, Backend_GetFieldNamesServer) error
+	ScanFields(context.Context, *ScanFieldsRequest) (*ScanFieldsResponse, error)
+	Keys(*KeysRequest, Backend_KeysServer) error
+}
+
+// UnimplementedBackendServer can be embedded in a BackendServer
+// implementation to satisfy the interface before all methods are written,
+// and to fail cleanly on any method a future version of the service adds.
+type UnimplementedBackendServer struct{}
+
+func (UnimplementedBackendServer) LockSession(Backend_LockSessionServer) error {
+	return status.Error(codes.Unimplemented, "method LockSession not implemented")
+}
+func (UnimplementedBackendServer) Has(context.Context, *HasRequest) (*HasResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Has not implemented")
+}
+func (UnimplementedBackendServer) DeleteHash(context.Context, *DeleteHashRequest) (*DeleteHashResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteHash not implemented")
+}
+func (UnimplementedBackendServer) PutHash(context.Context, *PutHashRequest) (*PutHashResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutHash not implemented")
+}
+func (UnimplementedBackendServer) GetHash(*GetHashRequest, Backend_GetHashServer) error {
+	return status.Error(codes.Unimplemented, "method GetHash not implemented")
+}
+func (UnimplementedBackendServer) GetHashAsArray(*GetHashRequest, Backend_GetHashAsArrayServer) error {
+	return status.Error(codes.Unimplemented, "method GetHashAsArray not implemented")
+}
+func (UnimplementedBackendServer) GetFields(context.Context, *GetFieldsRequest) (*GetFieldsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetFields not implemented")
+}
+func (UnimplementedBackendServer) GetFieldsAsArray(*GetFieldsRequest, Backend_GetFieldsAsArrayServer) error {
+	return status.Error(codes.Unimplemented, "method GetFieldsAsArray not implemented")
+}
+func (UnimplementedBackendServer) DeleteFields(context.Context, *DeleteFieldsRequest) (*DeleteFieldsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteFields not implemented")
+}
+func (UnimplementedBackendServer) GetFieldNames(*GetFieldNamesRequest, Backend_GetFieldNamesServer) error {
+	return status.Error(codes.Unimplemented, "method GetFieldNames not implemented")
+}
+func (UnimplementedBackendServer) ScanFields(context.Context, *ScanFieldsRequest) (*ScanFieldsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScanFields not implemented")
+}
+func (UnimplementedBackendServer) Keys(*KeysRequest, Backend_KeysServer) error {
+	return status.Error(codes.Unimplemented, "method Keys not implemented")
+}
+
+// RegisterBackendServer registers srv on s.
+func RegisterBackendServer(s grpc.ServiceRegistrar, srv BackendServer) {
+	s.RegisterService(&backendServiceDesc, srv)
+}
+
+type Backend_LockSessionServer interface {
+	Send(*LockSessionResponse) error
+	Recv() (*LockSessionRequest, error)
+	grpc.ServerStream
+}
+
+type backendLockSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendLockSessionServer) Send(m *LockSessionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *backendLockSessionServer) Recv() (*LockSessionRequest, error) {
+	m := new(LockSessionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Backend_LockSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BackendServer).LockSession(&backendLockSessionServer{stream})
+}
+
+func _Backend_Has_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Has(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/remote.Backend/Has"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).Has(ctx, req.(*HasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_DeleteHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).DeleteHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/remote.Backend/DeleteHash"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).DeleteHash(ctx, req.(*DeleteHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_PutHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).PutHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/remote.Backend/PutHash"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).PutHash(ctx, req.(*PutHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type Backend_GetHashServer interface {
+	Send(*Field) error
+	grpc.ServerStream
+}
+
+type backendGetHashServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendGetHashServer) Send(m *Field) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_GetHash_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetHashRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).GetHash(m, &backendGetHashServer{stream})
+}
+
+type Backend_GetHashAsArrayServer interface {
+	Send(*Field) error
+	grpc.ServerStream
+}
+
+type backendGetHashAsArrayServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendGetHashAsArrayServer) Send(m *Field) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_GetHashAsArray_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetHashRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).GetHashAsArray(m, &backendGetHashAsArrayServer{stream})
+}
+
+func _Backend_GetFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).GetFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/remote.Backend/GetFields"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).GetFields(ctx, req.(*GetFieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type Backend_GetFieldsAsArrayServer interface {
+	Send(*Field) error
+	grpc.ServerStream
+}
+
+type backendGetFieldsAsArrayServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendGetFieldsAsArrayServer) Send(m *Field) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_GetFieldsAsArray_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetFieldsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).GetFieldsAsArray(m, &backendGetFieldsAsArrayServer{stream})
+}
+
+func _Backend_DeleteFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).DeleteFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/remote.Backend/DeleteFields"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).DeleteFields(ctx, req.(*DeleteFieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type Backend_GetFieldNamesServer interface {
+	Send(*FieldName) error
+	grpc.ServerStream
+}
+
+type backendGetFieldNamesServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendGetFieldNamesServer) Send(m *FieldName) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_GetFieldNames_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetFieldNamesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).GetFieldNames(m, &backendGetFieldNamesServer{stream})
+}
+
+func _Backend_ScanFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanFieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).ScanFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/remote.Backend/ScanFields"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).ScanFields(ctx, req.(*ScanFieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type Backend_KeysServer interface {
+	Send(*Key) error
+	grpc.ServerStream
+}
+
+type backendKeysServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendKeysServer) Send(m *Key) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_Keys_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(KeysRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).Keys(m, &backendKeysServer{stream})
+}
+
+// backendServiceDesc is the grpc.ServiceDesc protoc-gen-go-grpc would have
+// generated for the Backend service in remote.proto.
+var backendServiceDesc = grpc.ServiceDesc{
+	ServiceName: "remote.Backend",
+	HandlerType: (*BackendServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Has", Handler: _Backend_Has_Handler},
+		{MethodName: "DeleteHash", Handler: _Backend_DeleteHash_Handler},
+		{MethodName: "PutHash", Handler: _Backend_PutHash_Handler},
+		{MethodName: "GetFields", Handler: _Backend_GetFields_Handler},
+		{MethodName: "DeleteFields", Handler: _Backend_DeleteFields_Handler},
+		{MethodName: "ScanFields", Handler: _Backend_ScanFields_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "LockSession", Handler: _Backend_LockSession_Handler, ServerStreams: true, ClientStreams: true},
+		{StreamName: "GetHash", Handler: _Backend_GetHash_Handler, ServerStreams: true},
+		{StreamName: "GetHashAsArray", Handler: _Backend_GetHashAsArray_Handler, ServerStreams: true},
+		{StreamName: "GetFieldsAsArray", Handler: _Backend_GetFieldsAsArray_Handler, ServerStreams: true},
+		{StreamName: "GetFieldNames", Handler: _Backend_GetFieldNames_Handler, ServerStreams: true},
+		{StreamName: "Keys", Handler: _Backend_Keys_Handler, ServerStreams: true},
+	},
+	Metadata: "remote.proto",
+}
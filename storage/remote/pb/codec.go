@@ -0,0 +1,233 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package pb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+// WireCodec is the encoding.Codec the Backend service uses on the wire. It
+// implements the real protobuf wire format described in remote.proto -
+// field tags, varints, length-delimited bytes and embedded messages -
+// generically over the message structs in this package by reflection,
+// rather than through code protoc-gen-go would generate: there's no protoc
+// or network access to run //go:generate against remote.proto in this
+// environment. The bytes it produces and consumes are ordinary protobuf on
+// the wire, interoperable with any protobuf client that has remote.proto;
+// only the Go-side (un)marshalling code was written by hand instead of
+// generated. Server and client must both install it explicitly
+// (grpc.ForceServerCodec, grpc.ForceCodec), since gRPC only auto-selects its
+// built-in "proto" codec, which expects the proto.Message interface real
+// generated types implement.
+//
+// Every exported struct field maps to the protobuf field numbered by its
+// position (first field is 1, second is 2, ...), matching remote.proto
+// field-for-field - the same convention protoc-gen-go would follow. Adding,
+// removing or reordering a struct field without making the same change to
+// remote.proto breaks that correspondence silently, so keep the two in
+// sync by hand whenever the service changes, same as for messages.go.
+type WireCodec struct{}
+
+func (WireCodec) Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	return marshalMessage(rv), nil
+}
+
+func (WireCodec) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return fmt.Errorf("pb: Unmarshal needs a pointer, got %T", v)
+	}
+	return unmarshalMessage(data, rv.Elem())
+}
+
+func (WireCodec) Name() string {
+	return "rodis-proto"
+}
+
+// marshalMessage encodes rv - a message struct's reflect.Value, not a
+// pointer to one - as a protobuf message: each field for which the
+// corresponding proto3 field is non-default is appended as a tag followed
+// by its value, in field-number order.
+func marshalMessage(rv reflect.Value) []byte {
+	var buf []byte
+	for i := 0; i < rv.NumField(); i++ {
+		buf = appendField(buf, i+1, rv.Field(i))
+	}
+	return buf
+}
+
+// appendField appends fv under fieldNum, proto3-style: a field left at its
+// zero value (false, 0, empty slice) is simply omitted.
+func appendField(buf []byte, fieldNum int, fv reflect.Value) []byte {
+	switch fv.Kind() {
+	case reflect.Bool:
+		if fv.Bool() {
+			buf = appendTag(buf, fieldNum, wireVarint)
+			buf = appendVarint(buf, 1)
+		}
+	case reflect.Uint32:
+		if n := fv.Uint(); n != 0 {
+			buf = appendTag(buf, fieldNum, wireVarint)
+			buf = appendVarint(buf, n)
+		}
+	case reflect.Int32:
+		if n := fv.Int(); n != 0 {
+			buf = appendTag(buf, fieldNum, wireVarint)
+			buf = appendVarint(buf, uint64(n))
+		}
+	case reflect.Slice:
+		et := fv.Type().Elem()
+		switch {
+		case et.Kind() == reflect.Uint8: // bytes
+			if fv.Len() > 0 {
+				buf = appendLengthDelimited(buf, fieldNum, fv.Bytes())
+			}
+		case et.Kind() == reflect.Slice && et.Elem().Kind() == reflect.Uint8: // repeated bytes
+			for i := 0; i < fv.Len(); i++ {
+				buf = appendLengthDelimited(buf, fieldNum, fv.Index(i).Bytes())
+			}
+		case et.Kind() == reflect.Ptr: // repeated embedded message
+			for i := 0; i < fv.Len(); i++ {
+				buf = appendLengthDelimited(buf, fieldNum, marshalMessage(fv.Index(i).Elem()))
+			}
+		default:
+			panic(fmt.Sprintf("pb: unsupported slice field type %s", fv.Type()))
+		}
+	default:
+		panic(fmt.Sprintf("pb: unsupported field type %s", fv.Type()))
+	}
+	return buf
+}
+
+// unmarshalMessage is the inverse of marshalMessage: it decodes data into
+// rv field by field, dispatching each record by its field number. Unknown
+// field numbers (a field remote.proto added later, sent by a newer peer)
+// are skipped, the same forward-compatibility proto3 itself guarantees.
+func unmarshalMessage(data []byte, rv reflect.Value) error {
+	for i := 0; i < len(data); {
+		tag, n := binary.Uvarint(data[i:])
+		if n <= 0 {
+			return fmt.Errorf("pb: malformed tag")
+		}
+		i += n
+
+		fieldNum := int(tag >> 3)
+		wireType := int(tag & 7)
+
+		switch wireType {
+		case wireVarint:
+			val, n := binary.Uvarint(data[i:])
+			if n <= 0 {
+				return fmt.Errorf("pb: malformed varint")
+			}
+			i += n
+			if err := setScalarField(rv, fieldNum, val); err != nil {
+				return err
+			}
+		case wireLengthDelimited:
+			l, n := binary.Uvarint(data[i:])
+			if n <= 0 {
+				return fmt.Errorf("pb: malformed length")
+			}
+			i += n
+			if i+int(l) > len(data) {
+				return fmt.Errorf("pb: truncated message")
+			}
+			raw := data[i : i+int(l)]
+			i += int(l)
+			if err := setBytesField(rv, fieldNum, raw); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("pb: unsupported wire type %d", wireType)
+		}
+	}
+	return nil
+}
+
+// setScalarField assigns a varint-encoded value to the field at fieldNum
+// (1-based, proto3 convention), ignoring field numbers past the message's
+// own fields.
+func setScalarField(rv reflect.Value, fieldNum int, val uint64) error {
+	if fieldNum < 1 || fieldNum > rv.NumField() {
+		return nil
+	}
+	fv := rv.Field(fieldNum - 1)
+	switch fv.Kind() {
+	case reflect.Bool:
+		fv.SetBool(val != 0)
+	case reflect.Uint32:
+		fv.SetUint(val)
+	case reflect.Int32:
+		fv.SetInt(int64(val))
+	default:
+		return fmt.Errorf("pb: field %d is not a varint type, got %s", fieldNum, fv.Type())
+	}
+	return nil
+}
+
+// setBytesField assigns a length-delimited record to the field at fieldNum,
+// appending for repeated fields (slices of bytes or of message pointers),
+// overwriting for a plain bytes field.
+func setBytesField(rv reflect.Value, fieldNum int, raw []byte) error {
+	if fieldNum < 1 || fieldNum > rv.NumField() {
+		return nil
+	}
+	fv := rv.Field(fieldNum - 1)
+	if fv.Kind() != reflect.Slice {
+		return fmt.Errorf("pb: field %d is not a bytes-shaped type, got %s", fieldNum, fv.Type())
+	}
+
+	et := fv.Type().Elem()
+	switch {
+	case et.Kind() == reflect.Uint8: // bytes
+		fv.SetBytes(append([]byte{}, raw...))
+	case et.Kind() == reflect.Slice && et.Elem().Kind() == reflect.Uint8: // repeated bytes
+		fv.Set(reflect.Append(fv, reflect.ValueOf(append([]byte{}, raw...))))
+	case et.Kind() == reflect.Ptr: // repeated embedded message
+		elem := reflect.New(et.Elem())
+		if err := unmarshalMessage(raw, elem.Elem()); err != nil {
+			return err
+		}
+		fv.Set(reflect.Append(fv, elem))
+	default:
+		return fmt.Errorf("pb: unsupported slice field type %s", fv.Type())
+	}
+	return nil
+}
+
+// Protobuf wire types used by the fields in this package's messages: varint
+// covers bool/uint32/int32, length-delimited covers bytes, repeated bytes
+// and embedded messages. The other two wire types protobuf defines (32-bit
+// and 64-bit fixed-width) aren't needed by any message here.
+const (
+	wireVarint          = 0
+	wireLengthDelimited = 2
+)
+
+func appendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+func appendTag(buf []byte, fieldNum, wireType int) []byte {
+	return appendVarint(buf, uint64(fieldNum)<<3|uint64(wireType))
+}
+
+func appendLengthDelimited(buf []byte, fieldNum int, data []byte) []byte {
+	buf = appendTag(buf, fieldNum, wireLengthDelimited)
+	buf = appendVarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package pb
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestWireCodecRoundTrip exercises the bytes, uint32 and repeated embedded
+// message field shapes WireCodec has to handle, round-tripping through
+// Marshal/Unmarshal the way grpc-go does on every call.
+func TestWireCodecRoundTrip(t *testing.T) {
+	in := &PutHashRequest{
+		Key:  []byte("mykey"),
+		Type: 7,
+		Hash: []*HashEntry{
+			{Field: []byte("f1"), Value: []byte("v1")},
+			{Field: []byte("f2"), Value: []byte("v2")},
+		},
+	}
+
+	data, err := (WireCodec{}).Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	out := new(PutHashRequest)
+	if err := (WireCodec{}).Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !bytes.Equal(out.Key, in.Key) || out.Type != in.Type || len(out.Hash) != len(in.Hash) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+	for i := range in.Hash {
+		if !bytes.Equal(out.Hash[i].Field, in.Hash[i].Field) || !bytes.Equal(out.Hash[i].Value, in.Hash[i].Value) {
+			t.Fatalf("round trip Hash[%d]: got %+v, want %+v", i, out.Hash[i], in.Hash[i])
+		}
+	}
+}
+
+// TestWireCodecRoundTripRepeatedBytes covers the [][]byte shape used by
+// GetFieldsRequest/DeleteFieldsRequest.
+func TestWireCodecRoundTripRepeatedBytes(t *testing.T) {
+	in := &GetFieldsRequest{
+		Key:    []byte("mykey"),
+		Fields: [][]byte{[]byte("f1"), []byte("f2"), []byte("f3")},
+	}
+
+	data, err := (WireCodec{}).Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	out := new(GetFieldsRequest)
+	if err := (WireCodec{}).Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !bytes.Equal(out.Key, in.Key) || len(out.Fields) != len(in.Fields) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+	for i := range in.Fields {
+		if !bytes.Equal(out.Fields[i], in.Fields[i]) {
+			t.Fatalf("round trip Fields[%d]: got %q, want %q", i, out.Fields[i], in.Fields[i])
+		}
+	}
+}
+
+// TestWireCodecZeroValuesOmitted confirms proto3's usual behavior: fields
+// left at their zero value round-trip as the zero value, not as an
+// explicitly-sent zero.
+func TestWireCodecZeroValuesOmitted(t *testing.T) {
+	data, err := (WireCodec{}).Marshal(&LockSessionRequest{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if len(data) != 0 {
+		t.Fatalf("an all-zero-value message should encode to zero bytes, got %d", len(data))
+	}
+
+	out := new(LockSessionRequest)
+	if err := (WireCodec{}).Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Mode != 0 || out.Release {
+		t.Fatalf("round trip of zero-value message: got %+v", out)
+	}
+}
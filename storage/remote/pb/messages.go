@@ -0,0 +1,120 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+// Package pb holds the wire types and gRPC plumbing for the Backend service
+// described in remote.proto. It's maintained by hand rather than generated
+// by protoc - there's no protoc or network access to run //go:generate in
+// this environment - but WireCodec (see codec.go) still puts real protobuf
+// wire format on the wire, so these struct definitions are the only
+// hand-written substitute for what protoc-gen-go would have produced.
+// Keeping the message shapes and RPC names in sync with remote.proto when
+// either changes is the maintainer's job, same as it would be for generated
+// code checked into version control.
+package pb
+
+// Field is one hash field/value pair.
+type Field struct {
+	Key   []byte
+	Value []byte
+}
+
+// FieldName is one hash field name.
+type FieldName struct {
+	Name []byte
+}
+
+// HashEntry is one field/value pair being written by PutHash.
+type HashEntry struct {
+	Field []byte
+	Value []byte
+}
+
+// Lock mode constants for LockSessionRequest.Mode.
+const (
+	LockModeWrite = 0
+	LockModeRead  = 1
+)
+
+// LockSessionRequest is sent twice per lock round on a LockSession stream:
+// once to acquire the lock (Mode set, Release false), and once to release it
+// (Release true). A stream can carry many such rounds, one after another.
+// The RPC being a stream, rather than separate Lock/Unlock calls, is what
+// ties the lock's lifetime to the stream's: see server.go.
+type LockSessionRequest struct {
+	Mode    int32
+	Release bool
+}
+
+// LockSessionResponse acknowledges that the lock requested by the current
+// round's acquiring LockSessionRequest has been acquired.
+type LockSessionResponse struct {
+	Acquired bool
+}
+
+type HasRequest struct {
+	Key []byte
+}
+
+type HasResponse struct {
+	Exists bool
+	Type   uint32
+}
+
+type DeleteHashRequest struct {
+	Key []byte
+}
+
+type DeleteHashResponse struct{}
+
+type PutHashRequest struct {
+	Key  []byte
+	Type uint32
+	Hash []*HashEntry
+}
+
+type PutHashResponse struct{}
+
+type GetHashRequest struct {
+	Key []byte
+}
+
+type GetFieldsRequest struct {
+	Key    []byte
+	Fields [][]byte
+}
+
+type GetFieldsResponse struct {
+	Fields []*Field
+}
+
+type DeleteFieldsRequest struct {
+	Key    []byte
+	Fields [][]byte
+}
+
+type DeleteFieldsResponse struct{}
+
+type GetFieldNamesRequest struct {
+	Key []byte
+}
+
+type ScanFieldsRequest struct {
+	Key        []byte
+	StartField []byte
+	Count      int32
+}
+
+type ScanFieldsResponse struct {
+	Fields    []*Field
+	NextField []byte
+}
+
+// KeysRequest has no fields: Keys always lists the whole keyspace.
+type KeysRequest struct{}
+
+// Key is one key name, streamed back by Keys.
+type Key struct {
+	Key []byte
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+// Package remote exposes a storage.Backend over gRPC (see remote.proto), so
+// several rodis command-frontends can share one persistent store, and
+// provides the client side, RemoteBackend, which satisfies storage.Backend
+// itself.
+//
+// The generated-code package, pb, is checked in by hand rather than produced
+// by protoc - see the package comment in pb/messages.go and the WireCodec
+// comment in pb/codec.go.
+package remote
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/rod6/rodis/storage"
+	"github.com/rod6/rodis/storage/remote/pb"
+)
+
+// Server implements pb.BackendServer over a storage.Backend.
+type Server struct {
+	pb.UnimplementedBackendServer
+
+	backend storage.Backend
+	mu      sync.RWMutex
+}
+
+// NewServer wraps backend for gRPC access.
+func NewServer(backend storage.Backend) *Server {
+	return &Server{backend: backend}
+}
+
+// Serve registers srv on a new gRPC server and serves it on lis, optionally
+// with mTLS if creds is non-nil. It blocks until the server stops.
+func Serve(lis net.Listener, srv *Server, creds credentials.TransportCredentials) error {
+	opts := []grpc.ServerOption{grpc.ForceServerCodec(pb.WireCodec{})}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	s := grpc.NewServer(opts...)
+	pb.RegisterBackendServer(s, srv)
+	return s.Serve(lis)
+}
+
+// LockSession ties each lock it grants to the lifetime of one gRPC stream,
+// so a client that crashes or disconnects mid-critical-section can never
+// leave s.mu locked forever: whichever unlock func a pending acquire/release
+// round returns runs as soon as the stream ends, however it ends.
+//
+// The stream is not one lock per connection though - a client reuses the
+// same stream across many Lock/Unlock (or RLock/RUnlock) cycles instead of
+// opening a fresh one each time, since opening a gRPC stream is the
+// expensive part of this RPC, not the lock itself. So this loops: for each
+// round, it reads one LockSessionRequest to acquire the lock in Mode, sends
+// back an acknowledgement, then reads the next LockSessionRequest (with
+// Release set, or simply the stream closing) to release it before looping
+// back to wait for the next round's acquire request.
+func (s *Server) LockSession(stream pb.Backend_LockSessionServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		unlock, err := s.acquire(req.Mode)
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(&pb.LockSessionResponse{Acquired: true}); err != nil {
+			unlock()
+			return err
+		}
+
+		_, err = stream.Recv()
+		unlock()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// acquire takes s.mu in the given mode and returns the matching unlock func.
+func (s *Server) acquire(mode int32) (func(), error) {
+	switch mode {
+	case pb.LockModeWrite:
+		s.mu.Lock()
+		return s.mu.Unlock, nil
+	case pb.LockModeRead:
+		s.mu.RLock()
+		return s.mu.RUnlock, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "remote: unknown lock mode %d", mode)
+	}
+}
+
+func (s *Server) Has(ctx context.Context, req *pb.HasRequest) (*pb.HasResponse, error) {
+	exists, tipe := s.backend.Has(req.Key)
+	return &pb.HasResponse{Exists: exists, Type: uint32(tipe)}, nil
+}
+
+func (s *Server) DeleteHash(ctx context.Context, req *pb.DeleteHashRequest) (*pb.DeleteHashResponse, error) {
+	s.backend.DeleteHash(req.Key)
+	return &pb.DeleteHashResponse{}, nil
+}
+
+func (s *Server) PutHash(ctx context.Context, req *pb.PutHashRequest) (*pb.PutHashResponse, error) {
+	hash := make(map[string][]byte, len(req.Hash))
+	for _, e := range req.Hash {
+		hash[string(e.Field)] = e.Value
+	}
+	s.backend.PutHash(req.Key, byte(req.Type), hash)
+	return &pb.PutHashResponse{}, nil
+}
+
+func (s *Server) GetHash(req *pb.GetHashRequest, stream pb.Backend_GetHashServer) error {
+	for field, value := range s.backend.GetHash(req.Key) {
+		if err := stream.Send(&pb.Field{Key: []byte(field), Value: value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetHashAsArray(req *pb.GetHashRequest, stream pb.Backend_GetHashAsArrayServer) error {
+	for _, f := range s.backend.GetHashAsArray(req.Key) {
+		if err := stream.Send(&pb.Field{Key: f.Key, Value: f.Value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetFields(ctx context.Context, req *pb.GetFieldsRequest) (*pb.GetFieldsResponse, error) {
+	hash := s.backend.GetFields(req.Key, req.Fields)
+	resp := &pb.GetFieldsResponse{}
+	for _, field := range req.Fields {
+		resp.Fields = append(resp.Fields, &pb.Field{Key: field, Value: hash[string(field)]})
+	}
+	return resp, nil
+}
+
+func (s *Server) GetFieldsAsArray(req *pb.GetFieldsRequest, stream pb.Backend_GetFieldsAsArrayServer) error {
+	for _, f := range s.backend.GetFieldsAsArray(req.Key, req.Fields) {
+		if err := stream.Send(&pb.Field{Key: f.Key, Value: f.Value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) DeleteFields(ctx context.Context, req *pb.DeleteFieldsRequest) (*pb.DeleteFieldsResponse, error) {
+	s.backend.DeleteFields(req.Key, req.Fields)
+	return &pb.DeleteFieldsResponse{}, nil
+}
+
+func (s *Server) GetFieldNames(req *pb.GetFieldNamesRequest, stream pb.Backend_GetFieldNamesServer) error {
+	for _, name := range s.backend.GetFieldNames(req.Key) {
+		if err := stream.Send(&pb.FieldName{Name: name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) ScanFields(ctx context.Context, req *pb.ScanFieldsRequest) (*pb.ScanFieldsResponse, error) {
+	fields, next := s.backend.ScanFields(req.Key, req.StartField, int(req.Count))
+	resp := &pb.ScanFieldsResponse{NextField: next}
+	for _, f := range fields {
+		resp.Fields = append(resp.Fields, &pb.Field{Key: f.Key, Value: f.Value})
+	}
+	return resp, nil
+}
+
+func (s *Server) Keys(req *pb.KeysRequest, stream pb.Backend_KeysServer) error {
+	for _, key := range s.backend.Keys() {
+		if err := stream.Send(&pb.Key{Key: key}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2020, Rod Dong <rod.dong@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by The MIT License.
+
+package storage
+
+import "sync"
+
+// Watcher tracks a monotonically increasing version per key, bumped every
+// time the key is written or deleted through a WatchedBackend. WATCH/EXEC
+// use it to detect whether a watched key changed since it was watched.
+type Watcher struct {
+	mu       sync.Mutex
+	versions map[string]uint64
+}
+
+// NewWatcher creates an empty Watcher.
+func NewWatcher() *Watcher {
+	return &Watcher{versions: make(map[string]uint64)}
+}
+
+// Version returns key's current version (zero if it was never bumped).
+func (w *Watcher) Version(key []byte) uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.versions[string(key)]
+}
+
+func (w *Watcher) bump(key []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.versions[string(key)]++
+}
+
+// WatchedBackend wraps a Backend so every write or delete bumps the
+// matching key's version in a shared Watcher, whether it came from a plain
+// command or from a CacheDB transaction flushing into this backend.
+// Read-only methods, including Keys, aren't overridden here - they're
+// promoted straight through to the embedded Backend.
+type WatchedBackend struct {
+	Backend
+	watch *Watcher
+}
+
+// NewWatchedBackend wraps parent so its mutations are tracked by watch.
+func NewWatchedBackend(parent Backend, watch *Watcher) *WatchedBackend {
+	return &WatchedBackend{Backend: parent, watch: watch}
+}
+
+func (w *WatchedBackend) PutHash(key []byte, tipe byte, hash map[string][]byte) {
+	w.Backend.PutHash(key, tipe, hash)
+	w.watch.bump(key)
+}
+
+func (w *WatchedBackend) DeleteFields(key []byte, fields [][]byte) {
+	w.Backend.DeleteFields(key, fields)
+	w.watch.bump(key)
+}
+
+func (w *WatchedBackend) DeleteHash(key []byte) {
+	w.Backend.DeleteHash(key)
+	w.watch.bump(key)
+}
+
+var _ Backend = (*WatchedBackend)(nil)